@@ -7,7 +7,7 @@
 //   - Configuration management with environment variable support
 //   - Structured JSON logging with configurable levels
 //   - Distributed crawling using Redis for URL queue management
-//   - Kafka integration for downstream content processing
+//   - Pluggable message queue (Kafka or RabbitMQ) for downstream content processing
 //   - HTTP monitoring endpoints for health checks and metrics
 //   - Graceful shutdown handling with configurable timeouts
 //
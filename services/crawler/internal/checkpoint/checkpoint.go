@@ -0,0 +1,112 @@
+// Package checkpoint periodically snapshots a crawl's stats counters and
+// seed configuration to Redis, so a pod restart under the same RUN_ID
+// restores those running totals instead of silently resetting them to
+// zero. It deliberately covers only the state that lives in process memory
+// (the atomic counters in metrics.Metrics) - the frontier itself (pending
+// queue, visited markers, requeued set) already persists independently of
+// this package, namespaced under RunID by storage.go, and is picked back up
+// automatically just by reusing that RunID.
+package checkpoint
+
+import (
+	// Stdlib
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	// Third-party
+	"github.com/redis/go-redis/v9"
+)
+
+// Snapshot is the point-in-time state persisted by Save and returned by Load.
+type Snapshot struct {
+	// StartURLs and CrawlDepth record the seed configuration a run started
+	// with, so a resumed run can be warned if it's about to continue under
+	// a different one.
+	StartURLs  string
+	CrawlDepth int
+
+	PagesProcessed  int64
+	PagesSuccessful int64
+	PagesFailed     int64
+	PagesRequeued   int64
+	KafkaSuccessful int64
+	KafkaFailed     int64
+	KafkaErrored    int64
+	RedisSuccessful int64
+	RedisFailed     int64
+	RedisErrored    int64
+
+	SavedAt time.Time
+}
+
+// Manager reads and writes a single run's Snapshot to a Redis hash keyed
+// under its RunID, sharing storage.go's "crawler:{<run-id>}:..." hash-tag
+// convention so the checkpoint lives on the same Cluster shard as the run's
+// visited, requeued and retry keys. The pending queues use the separate
+// "{<run-id>:pending_urls}" hash tag and can land on a different shard.
+type Manager struct {
+	redis redis.UniversalClient
+	runID string
+}
+
+// NewManager creates a Manager that checkpoints the given run.
+func NewManager(redisClient redis.UniversalClient, runID string) *Manager {
+	return &Manager{redis: redisClient, runID: runID}
+}
+
+func (m *Manager) key() string {
+	return fmt.Sprintf("crawler:{%s}:checkpoint", m.runID)
+}
+
+// Save writes snap to Redis, overwriting this run's previous checkpoint.
+func (m *Manager) Save(ctx context.Context, snap Snapshot) error {
+	fields := map[string]any{
+		"start_urls":       snap.StartURLs,
+		"crawl_depth":      snap.CrawlDepth,
+		"pages_processed":  snap.PagesProcessed,
+		"pages_successful": snap.PagesSuccessful,
+		"pages_failed":     snap.PagesFailed,
+		"pages_requeued":   snap.PagesRequeued,
+		"kafka_successful": snap.KafkaSuccessful,
+		"kafka_failed":     snap.KafkaFailed,
+		"kafka_errored":    snap.KafkaErrored,
+		"redis_successful": snap.RedisSuccessful,
+		"redis_failed":     snap.RedisFailed,
+		"redis_errored":    snap.RedisErrored,
+		"saved_at":         time.Now().UTC().Format(time.RFC3339),
+	}
+	return m.redis.HSet(ctx, m.key(), fields).Err()
+}
+
+// Load returns the current run's checkpoint, or nil if none has been saved
+// yet - either a brand-new run, or one that crashed before its first
+// periodic Save.
+func (m *Manager) Load(ctx context.Context) (*Snapshot, error) {
+	raw, err := m.redis.HGetAll(ctx, m.key()).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	snap := &Snapshot{StartURLs: raw["start_urls"]}
+	snap.CrawlDepth, _ = strconv.Atoi(raw["crawl_depth"])
+	snap.PagesProcessed, _ = strconv.ParseInt(raw["pages_processed"], 10, 64)
+	snap.PagesSuccessful, _ = strconv.ParseInt(raw["pages_successful"], 10, 64)
+	snap.PagesFailed, _ = strconv.ParseInt(raw["pages_failed"], 10, 64)
+	snap.PagesRequeued, _ = strconv.ParseInt(raw["pages_requeued"], 10, 64)
+	snap.KafkaSuccessful, _ = strconv.ParseInt(raw["kafka_successful"], 10, 64)
+	snap.KafkaFailed, _ = strconv.ParseInt(raw["kafka_failed"], 10, 64)
+	snap.KafkaErrored, _ = strconv.ParseInt(raw["kafka_errored"], 10, 64)
+	snap.RedisSuccessful, _ = strconv.ParseInt(raw["redis_successful"], 10, 64)
+	snap.RedisFailed, _ = strconv.ParseInt(raw["redis_failed"], 10, 64)
+	snap.RedisErrored, _ = strconv.ParseInt(raw["redis_errored"], 10, 64)
+	if t, err := time.Parse(time.RFC3339, raw["saved_at"]); err == nil {
+		snap.SavedAt = t
+	}
+
+	return snap, nil
+}
@@ -0,0 +1,177 @@
+// Package circuitbreaker implements a simple per-host failure-rate circuit
+// breaker: once a host's failure rate over a sliding window exceeds a
+// threshold, the breaker opens and fail-fasts further requests to that host
+// for a cooldown period, then allows a single half-open probe to decide
+// whether to close again or re-open.
+package circuitbreaker
+
+import (
+	// Stdlib
+	"sync"
+	"time"
+)
+
+// State is the lifecycle state of a single host's breaker.
+type State string
+
+const (
+	StateClosed   State = "closed"    // Requests flow normally; outcomes are tracked.
+	StateOpen     State = "open"      // Requests are fail-fasted until Cooldown elapses.
+	StateHalfOpen State = "half_open" // A single probe request is allowed through to decide closed vs. open.
+)
+
+// outcome is a single timestamped request result, pruned from hostState.outcomes
+// once older than the breaker's Window.
+type outcome struct {
+	at      time.Time
+	success bool
+}
+
+// hostState is one host's breaker bookkeeping.
+type hostState struct {
+	state    State
+	openedAt time.Time
+	outcomes []outcome
+	probing  bool // true while a half-open probe request is in flight
+}
+
+// Breaker tracks independent circuit-breaker state per host, guarded by a
+// single mutex since per-host contention is expected to be low relative to
+// the cost of per-host locks.
+type Breaker struct {
+	threshold   float64
+	window      time.Duration
+	minRequests int
+	cooldown    time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+// New creates a Breaker that opens a host once its failure rate over window
+// exceeds threshold (0-1), provided at least minRequests outcomes were
+// recorded in that window, and keeps it open for cooldown before probing.
+func New(threshold float64, window time.Duration, minRequests int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		threshold:   threshold,
+		window:      window,
+		minRequests: minRequests,
+		cooldown:    cooldown,
+		hosts:       make(map[string]*hostState),
+	}
+}
+
+// Allow reports whether a request to host should proceed. A closed breaker
+// always allows; an open breaker fail-fasts until cooldown has elapsed,
+// at which point it transitions to half-open and allows exactly one probe
+// through (further callers are fail-fasted until that probe's result is
+// recorded via RecordResult).
+func (b *Breaker) Allow(host string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	hs := b.hosts[host]
+	if hs == nil {
+		return true // no history yet; treat as closed
+	}
+
+	switch hs.state {
+	case StateOpen:
+		if time.Since(hs.openedAt) < b.cooldown {
+			return false
+		}
+		hs.state = StateHalfOpen
+		hs.probing = true
+		return true
+	case StateHalfOpen:
+		return !hs.probing
+	default: // StateClosed
+		return true
+	}
+}
+
+// RecordResult records the outcome of a request to host that Allow let
+// through, updating the breaker's state for subsequent calls.
+func (b *Breaker) RecordResult(host string, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	hs := b.hosts[host]
+	if hs == nil {
+		hs = &hostState{state: StateClosed}
+		b.hosts[host] = hs
+	}
+
+	switch hs.state {
+	case StateHalfOpen:
+		hs.probing = false
+		if success {
+			hs.state = StateClosed
+			hs.outcomes = nil
+		} else {
+			hs.state = StateOpen
+			hs.openedAt = time.Now()
+			hs.outcomes = nil
+		}
+		return
+	case StateOpen:
+		// Allow is fail-fasting everything but the half-open probe, so a
+		// result shouldn't reach here; ignore defensively.
+		return
+	}
+
+	now := time.Now()
+	hs.outcomes = append(prune(hs.outcomes, now, b.window), outcome{at: now, success: success})
+
+	if len(hs.outcomes) < b.minRequests {
+		return
+	}
+
+	failures := 0
+	for _, o := range hs.outcomes {
+		if !o.success {
+			failures++
+		}
+	}
+	if float64(failures)/float64(len(hs.outcomes)) > b.threshold {
+		hs.state = StateOpen
+		hs.openedAt = now
+		hs.outcomes = nil
+	}
+}
+
+// prune drops outcomes older than window relative to now.
+func prune(outcomes []outcome, now time.Time, window time.Duration) []outcome {
+	kept := outcomes[:0]
+	for _, o := range outcomes {
+		if now.Sub(o.at) <= window {
+			kept = append(kept, o)
+		}
+	}
+	return kept
+}
+
+// State returns host's current breaker state, StateClosed if it has no
+// recorded history yet.
+func (b *Breaker) State(host string) State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if hs := b.hosts[host]; hs != nil {
+		return hs.state
+	}
+	return StateClosed
+}
+
+// States returns a snapshot of every host with recorded breaker history,
+// for the monitor endpoint to expose operationally.
+func (b *Breaker) States() map[string]State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	snapshot := make(map[string]State, len(b.hosts))
+	for host, hs := range b.hosts {
+		snapshot[host] = hs.state
+	}
+	return snapshot
+}
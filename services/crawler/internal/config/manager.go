@@ -0,0 +1,284 @@
+package config
+
+import (
+	// Stdlib
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	// Third-party
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+)
+
+// Live-reloadable vs. restart-required fields.
+//
+// Manager re-reads and re-validates the entire Config on every reload and
+// swaps it in wholesale, but most subsystems only ever read their piece of
+// it once at startup (the Kafka/RabbitMQ producer, Redis client, DNS
+// resolver, and monitor HTTP server are all built from a one-time snapshot).
+// Changing those fields via CONFIG_FILE/SIGHUP is accepted - Current()
+// reflects the new value immediately - but has no practical effect until
+// the process restarts:
+//
+//	Restart-required: QueueBackend, KafkaBrokers/KafkaTopic/KafkaRetryMax,
+//	KafkaProduceMode/KafkaMaxBufferedRecords/KafkaBufferFullPolicy/
+//	KafkaProduceTimeout/KafkaCompression/KafkaAcks/KafkaRequiredAcksTimeout/
+//	KafkaTLSEnable/KafkaCACert/KafkaClientCert/KafkaClientKey/
+//	KafkaInsecureSkipVerify/KafkaSASLMechanism/KafkaSASLUser/KafkaSASLPassword
+//	(all baked into the Sarama producer at construction), RabbitMQ*,
+//	RedisHost/RedisPort/RedisPassword/RedisDB/RedisRetryMax/RedisMode/
+//	RedisClusterAddrs/RedisSentinelAddrs/RedisSentinelMasterName/
+//	RedisSentinelPassword (the redis.UniversalClient is built once in
+//	initializeRedis), CacheLRUSize/CacheTTL (the membershipCache LRUs are
+//	sized once at startup), MonitorPort,
+//	DNSMode/DNSEndpoint/DNSBootstrapIPs, OTel* (the tracer provider is
+//	configured once at startup), MetricsSinks/StatsD* (the metrics.Sink
+//	slice and any StatsD UDP connection are built once in metrics.NewMetrics),
+//	WARCEnable/WARCOutputDir/WARCFilePrefix/WARCMaxFileSizeMB (the warc.Writer,
+//	if any, is opened once in New()), RunID (baked into every Redis key the
+//	crawler touches at construction; changing it live would silently orphan
+//	the in-flight run's keys instead of resuming or renaming them), Resume
+//	(only consulted once in New(), to decide whether to purge RunID's prior
+//	state before the first URL is ever enqueued), RulesFile
+//	(the configwatch.Watcher, if any, is started once in New() against
+//	whichever path was configured at startup), RetryMaxAttempts/RetryBaseDelay/
+//	RetryMaxDelay/CircuitBreakerFailureThreshold/CircuitBreakerWindow/
+//	CircuitBreakerMinRequests/CircuitBreakerCooldown (retry.Manager and
+//	circuitbreaker.Breaker are both constructed once in New()).
+//
+// The crawler and validator packages, on the other hand, read the
+// following fields through Manager.Current() (or via a callback registered
+// with OnChange) on every use, so changes take effect on the next fetch:
+//
+//	Live-reloadable: MaxConcurrency (the queue feeder's own backpressure
+//	check; Colly's internal limiter still needs a restart, see colly.go),
+//	MaxContentSize, URLWhitelist/URLBlacklist, LogLevel,
+//	ReadinessBacklogThreshold, CrawlDepth (the Redis queue's own depth
+//	checks in storage.go/queue.go; Colly's MaxDepth limiter is baked in at
+//	collector construction and still needs a restart), DNSCacheTTL,
+//	AllowPrivateIPs, AllowLoopback (all three pushed into the shared
+//	URLValidator).
+//
+// RequestDelay and RequestTimeout are parsed and validated on every reload
+// like everything else, but nothing currently reads them back out live -
+// Colly's rate limiter and HTTP client only pick them up at collector
+// construction - so changing either still needs a restart to take effect.
+//
+// restartRequiredFields lists the struct field names above the
+// Live-reloadable line, plus RequestDelay and RequestTimeout;
+// logRestartRequiredChanges uses it to warn
+// operators when a reload changed something that won't take effect until
+// the process restarts.
+var restartRequiredFields = []string{
+	"QueueBackend",
+	"KafkaBrokers", "KafkaTopic", "KafkaRetryMax",
+	"KafkaProduceMode", "KafkaMaxBufferedRecords", "KafkaBufferFullPolicy",
+	"KafkaProduceTimeout", "KafkaCompression", "KafkaAcks", "KafkaRequiredAcksTimeout",
+	"KafkaTLSEnable", "KafkaCACert", "KafkaClientCert", "KafkaClientKey",
+	"KafkaInsecureSkipVerify", "KafkaSASLMechanism", "KafkaSASLUser", "KafkaSASLPassword",
+	"RabbitMQURI", "RabbitMQExchange", "RabbitMQRoutingKey", "RabbitMQQueue",
+	"RedisHost", "RedisPort", "RedisPassword", "RedisDB", "RedisRetryMax", "RedisMode",
+	"RedisClusterAddrs", "RedisSentinelAddrs", "RedisSentinelMasterName", "RedisSentinelPassword",
+	"CacheLRUSize", "CacheTTL",
+	"MonitorPort",
+	"RequestDelay", "RequestTimeout",
+	"DNSMode", "DNSEndpoint", "DNSBootstrapIPs",
+	"OTelServiceName", "OTelExporterOTLP", "OTelSampleRatio",
+	"MetricsSinks", "StatsDHost", "StatsDPort", "StatsDPrefix", "StatsDFlushInterval",
+	"WARCEnable", "WARCOutputDir", "WARCFilePrefix", "WARCMaxFileSizeMB",
+	"RunID", "Resume",
+	"RulesFile",
+	"RetryMaxAttempts", "RetryBaseDelay", "RetryMaxDelay",
+	"CircuitBreakerFailureThreshold", "CircuitBreakerWindow", "CircuitBreakerMinRequests", "CircuitBreakerCooldown",
+}
+
+// Manager watches Config.ConfigFile for changes via fsnotify and also
+// reloads on SIGHUP, re-parsing and re-validating the full configuration
+// (environment variables plus the config file) on each trigger. A
+// successful reload is swapped into an atomic.Pointer[Config]; a failed one
+// (parse error or failed Validate) is logged and discarded, leaving the
+// previous configuration in place. Subsystems read the live configuration
+// via Current() instead of holding their own *Config.
+type Manager struct {
+	log *logrus.Logger
+
+	current atomic.Pointer[Config]
+
+	onReload func(outcome string)       // called "success"/"failure" after every reload attempt
+	onChange func(old, next *Config)    // called with the old and new config after a successful swap
+
+	watcher *fsnotify.Watcher // nil if Config.ConfigFile is unset
+	sigCh   chan os.Signal
+
+	done     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewManager creates a Manager seeded with the already-loaded cfg (as
+// returned by InitializeConfig). Call Start to begin watching for changes.
+func NewManager(cfg *Config, log *logrus.Logger) *Manager {
+	m := &Manager{log: log, done: make(chan struct{})}
+	m.current.Store(cfg)
+	return m
+}
+
+// Current returns the most recently validated configuration.
+func (m *Manager) Current() *Config {
+	return m.current.Load()
+}
+
+// OnReload registers a callback invoked with "success" or "failure" after
+// every reload attempt, e.g. to increment a crawler_config_reloads_total
+// Prometheus counter.
+func (m *Manager) OnReload(fn func(outcome string)) {
+	m.onReload = fn
+}
+
+// OnChange registers a callback invoked with the old and new configuration
+// after a successful reload, e.g. to push a new LogLevel into the shared
+// logger or new URLWhitelist/URLBlacklist values into the URL validator.
+func (m *Manager) OnChange(fn func(old, next *Config)) {
+	m.onChange = fn
+}
+
+// Start begins watching for SIGHUP and, if Current().ConfigFile is set,
+// filesystem changes to that file. It returns once the watchers are set up;
+// the actual watching runs in a background goroutine until Stop is called.
+func (m *Manager) Start() error {
+	m.sigCh = make(chan os.Signal, 1)
+	signal.Notify(m.sigCh, syscall.SIGHUP)
+
+	if path := m.Current().ConfigFile; path != "" {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return err
+		}
+		// Watch the containing directory rather than the file itself:
+		// editors and config-management tools commonly replace a file via
+		// rename-into-place, which fsnotify can't follow if it's watching
+		// the (now-deleted) inode directly.
+		if err := watcher.Add(filepath.Dir(path)); err != nil {
+			_ = watcher.Close()
+			return err
+		}
+		m.watcher = watcher
+	}
+
+	m.wg.Add(1)
+	go m.run()
+	return nil
+}
+
+// Stop halts the watch goroutine and releases the fsnotify watcher, if any.
+func (m *Manager) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.done)
+		signal.Stop(m.sigCh)
+		if m.watcher != nil {
+			_ = m.watcher.Close()
+		}
+	})
+	m.wg.Wait()
+}
+
+// run is the Manager's background loop, reacting to SIGHUP and fsnotify
+// events on the watched config file until Stop closes m.done.
+func (m *Manager) run() {
+	defer m.wg.Done()
+
+	target := filepath.Clean(m.Current().ConfigFile)
+
+	var events chan fsnotify.Event
+	var errs chan error
+	if m.watcher != nil {
+		events = m.watcher.Events
+		errs = m.watcher.Errors
+	}
+
+	for {
+		select {
+		case <-m.done:
+			return
+
+		case sig := <-m.sigCh:
+			m.log.WithField("signal", sig.String()).Info("Received SIGHUP, reloading configuration")
+			m.reload()
+
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			if filepath.Clean(event.Name) != target {
+				continue // a sibling file in the same directory changed
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			m.log.WithField("file", event.Name).Info("Config file changed, reloading configuration")
+			m.reload()
+
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			m.log.WithError(err).Warn("Config file watcher error")
+		}
+	}
+}
+
+// reload re-runs InitializeConfig (re-reading the environment and
+// Config.ConfigFile) and, if it validates, atomically swaps it in and
+// fires onChange. A failure is logged and leaves Current() untouched.
+func (m *Manager) reload() {
+	next, err := InitializeConfig()
+	if err != nil {
+		m.log.WithError(err).Error("Configuration reload failed; keeping previous configuration")
+		if m.onReload != nil {
+			m.onReload("failure")
+		}
+		return
+	}
+
+	old := m.current.Swap(next)
+	m.log.Info("Configuration reloaded successfully")
+	m.logRestartRequiredChanges(old, next)
+	if m.onReload != nil {
+		m.onReload("success")
+	}
+	if m.onChange != nil {
+		m.onChange(old, next)
+	}
+}
+
+// logRestartRequiredChanges compares old and next across restartRequiredFields
+// and logs a warning naming any that changed, since Current() now reflects
+// the new value but the subsystem that cares about it was already built
+// from the old one and won't notice until the process restarts.
+func (m *Manager) logRestartRequiredChanges(old, next *Config) {
+	oldVal := reflect.ValueOf(*old)
+	nextVal := reflect.ValueOf(*next)
+
+	var changed []string
+	for _, name := range restartRequiredFields {
+		o := oldVal.FieldByName(name)
+		n := nextVal.FieldByName(name)
+		if !o.IsValid() || !n.IsValid() {
+			continue
+		}
+		if !reflect.DeepEqual(o.Interface(), n.Interface()) {
+			changed = append(changed, name)
+		}
+	}
+
+	if len(changed) > 0 {
+		m.log.WithField("fields", changed).Warn("Config reload changed fields that require a restart to take effect")
+	}
+}
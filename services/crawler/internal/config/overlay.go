@@ -0,0 +1,436 @@
+package config
+
+import (
+	// Stdlib
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	// Third-party
+	"gopkg.in/yaml.v3"
+)
+
+// configOverlay mirrors most of Config, with every field optional (a nil
+// pointer means "not set in the file, leave the envconfig-derived value
+// alone"). It has no fields for Resume, RulesFile, ConfigFile, the Pipeline*,
+// Retry* or CircuitBreaker* settings; those keep their envconfig values.
+// Durations are plain strings (parsed the same way env vars are, e.g.
+// "100ms") so the file format doesn't need a custom (un)marshaler.
+//
+// Field names use the lowercased envconfig tag (e.g. "max_concurrency")
+// rather than Go's exported field name, matching the convention operators
+// already know from the environment variables.
+type configOverlay struct {
+	QueueBackend *string `yaml:"queue_backend" json:"queue_backend"`
+
+	KafkaBrokers  *string `yaml:"kafka_brokers" json:"kafka_brokers"`
+	KafkaTopic    *string `yaml:"kafka_topic_html" json:"kafka_topic_html"`
+	KafkaRetryMax *int    `yaml:"kafka_retry_max" json:"kafka_retry_max"`
+
+	KafkaProduceMode         *string `yaml:"kafka_produce_mode" json:"kafka_produce_mode"`
+	KafkaMaxBufferedRecords  *int    `yaml:"kafka_max_buffered_records" json:"kafka_max_buffered_records"`
+	KafkaBufferFullPolicy    *string `yaml:"kafka_buffer_full_policy" json:"kafka_buffer_full_policy"`
+	KafkaProduceTimeout      *string `yaml:"kafka_produce_timeout" json:"kafka_produce_timeout"`
+	KafkaCompression         *string `yaml:"kafka_compression" json:"kafka_compression"`
+	KafkaAcks                *string `yaml:"kafka_acks" json:"kafka_acks"`
+	KafkaRequiredAcksTimeout *string `yaml:"kafka_required_acks_timeout" json:"kafka_required_acks_timeout"`
+
+	KafkaTLSEnable          *bool   `yaml:"kafka_tls_enable" json:"kafka_tls_enable"`
+	KafkaCACert             *string `yaml:"kafka_ca_cert" json:"kafka_ca_cert"`
+	KafkaClientCert         *string `yaml:"kafka_client_cert" json:"kafka_client_cert"`
+	KafkaClientKey          *string `yaml:"kafka_client_key" json:"kafka_client_key"`
+	KafkaInsecureSkipVerify *bool   `yaml:"kafka_insecure_skip_verify" json:"kafka_insecure_skip_verify"`
+	KafkaSASLMechanism      *string `yaml:"kafka_sasl_mechanism" json:"kafka_sasl_mechanism"`
+	KafkaSASLUser           *string `yaml:"kafka_sasl_user" json:"kafka_sasl_user"`
+	KafkaSASLPassword       *string `yaml:"kafka_sasl_password" json:"kafka_sasl_password"`
+
+	RabbitMQURI        *string `yaml:"rabbitmq_uri" json:"rabbitmq_uri"`
+	RabbitMQExchange   *string `yaml:"rabbitmq_exchange" json:"rabbitmq_exchange"`
+	RabbitMQRoutingKey *string `yaml:"rabbitmq_routing_key" json:"rabbitmq_routing_key"`
+	RabbitMQQueue      *string `yaml:"rabbitmq_queue" json:"rabbitmq_queue"`
+
+	RedisHost     *string `yaml:"redis_host" json:"redis_host"`
+	RedisPort     *int    `yaml:"redis_port" json:"redis_port"`
+	RedisPassword *string `yaml:"redis_password" json:"redis_password"`
+	RedisDB       *int    `yaml:"redis_db" json:"redis_db"`
+	RedisTimeout  *string `yaml:"redis_timeout" json:"redis_timeout"`
+	RedisRetryMax *int    `yaml:"redis_retry_max" json:"redis_retry_max"`
+
+	RedisMode               *string `yaml:"redis_mode" json:"redis_mode"`
+	RedisClusterAddrs       *string `yaml:"redis_cluster_addrs" json:"redis_cluster_addrs"`
+	RedisSentinelAddrs      *string `yaml:"redis_sentinel_addrs" json:"redis_sentinel_addrs"`
+	RedisSentinelMasterName *string `yaml:"redis_sentinel_master_name" json:"redis_sentinel_master_name"`
+	RedisSentinelPassword   *string `yaml:"redis_sentinel_password" json:"redis_sentinel_password"`
+
+	CacheLRUSize *int    `yaml:"cache_lru_size" json:"cache_lru_size"`
+	CacheTTL     *string `yaml:"cache_ttl" json:"cache_ttl"`
+	RunID        *string `yaml:"run_id" json:"run_id"`
+
+	StartURLs    *string `yaml:"start_urls" json:"start_urls"`
+	CrawlDepth   *int    `yaml:"crawl_depth" json:"crawl_depth"`
+	MaxPages     *int64  `yaml:"max_pages" json:"max_pages"`
+	URLWhitelist *string `yaml:"url_whitelist" json:"url_whitelist"`
+	URLBlacklist *string `yaml:"url_blacklist" json:"url_blacklist"`
+
+	URLQueryAllowlist *string `yaml:"url_query_allowlist" json:"url_query_allowlist"`
+	URLQueryDenylist  *string `yaml:"url_query_denylist" json:"url_query_denylist"`
+
+	MaxConcurrency *int    `yaml:"max_concurrency" json:"max_concurrency"`
+	RequestTimeout *string `yaml:"request_timeout" json:"request_timeout"`
+	RequestDelay   *string `yaml:"request_delay" json:"request_delay"`
+	MaxContentSize *int    `yaml:"max_content_size" json:"max_content_size"`
+
+	LogLevel    *string `yaml:"log_level" json:"log_level"`
+	UserAgent   *string `yaml:"user_agent" json:"user_agent"`
+	EnableDebug *bool   `yaml:"enable_debug" json:"enable_debug"`
+	MonitorPort *int    `yaml:"monitor_port" json:"monitor_port"`
+
+	EnableProfiling *bool `yaml:"enable_profiling" json:"enable_profiling"`
+
+	ReadinessBacklogThreshold *int64 `yaml:"readiness_backlog_threshold" json:"readiness_backlog_threshold"`
+
+	OTelServiceName  *string  `yaml:"otel_service_name" json:"otel_service_name"`
+	OTelExporterOTLP *string  `yaml:"otel_exporter_otlp_endpoint" json:"otel_exporter_otlp_endpoint"`
+	OTelSampleRatio  *float64 `yaml:"otel_sample_ratio" json:"otel_sample_ratio"`
+
+	APITokens      *string `yaml:"api_tokens" json:"api_tokens"`
+	APITokenScopes *string `yaml:"api_token_scopes" json:"api_token_scopes"`
+
+	DNSMode         *string `yaml:"dns_mode" json:"dns_mode"`
+	DNSEndpoint     *string `yaml:"dns_endpoint" json:"dns_endpoint"`
+	DNSBootstrapIPs *string `yaml:"dns_bootstrap_ips" json:"dns_bootstrap_ips"`
+	DNSCacheTTL     *string `yaml:"dns_cache_ttl" json:"dns_cache_ttl"`
+	AllowPrivateIPs *bool   `yaml:"allow_private_ips" json:"allow_private_ips"`
+	AllowLoopback   *bool   `yaml:"allow_loopback" json:"allow_loopback"`
+
+	MetricsSinks *string `yaml:"metrics_sinks" json:"metrics_sinks"`
+
+	StatsDHost          *string `yaml:"statsd_host" json:"statsd_host"`
+	StatsDPort          *int    `yaml:"statsd_port" json:"statsd_port"`
+	StatsDPrefix        *string `yaml:"statsd_prefix" json:"statsd_prefix"`
+	StatsDFlushInterval *string `yaml:"statsd_flush_interval" json:"statsd_flush_interval"`
+
+	WARCEnable        *bool   `yaml:"warc_enable" json:"warc_enable"`
+	WARCOutputDir     *string `yaml:"warc_output_dir" json:"warc_output_dir"`
+	WARCFilePrefix    *string `yaml:"warc_file_prefix" json:"warc_file_prefix"`
+	WARCMaxFileSizeMB *int64  `yaml:"warc_max_file_size_mb" json:"warc_max_file_size_mb"`
+}
+
+// parseOverlayFile reads and decodes path (YAML for .yaml/.yml, JSON for
+// .json; any other extension is an error) into a configOverlay.
+func parseOverlayFile(path string) (*configOverlay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	overlay := &configOverlay{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, overlay); err != nil {
+			return nil, fmt.Errorf("parsing YAML config file: %w", err)
+		}
+	case ".json":
+		if err := json.Unmarshal(data, overlay); err != nil {
+			return nil, fmt.Errorf("parsing JSON config file: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config file extension %q (expected .yaml, .yml or .json)", ext)
+	}
+
+	return overlay, nil
+}
+
+// applyOverlayFile parses path and layers every field it sets onto cfg,
+// in place. It does not validate the result; callers must call
+// cfg.Validate() afterwards.
+func applyOverlayFile(cfg *Config, path string) error {
+	overlay, err := parseOverlayFile(path)
+	if err != nil {
+		return err
+	}
+	return overlay.applyTo(cfg)
+}
+
+// applyTo copies every set field of o onto cfg, parsing duration strings
+// along the way. Returns an error if a duration string doesn't parse; all
+// other validation is left to Config.Validate().
+func (o *configOverlay) applyTo(cfg *Config) error {
+	if o.QueueBackend != nil {
+		cfg.QueueBackend = *o.QueueBackend
+	}
+
+	if o.KafkaBrokers != nil {
+		cfg.KafkaBrokers = *o.KafkaBrokers
+	}
+	if o.KafkaTopic != nil {
+		cfg.KafkaTopic = *o.KafkaTopic
+	}
+	if o.KafkaRetryMax != nil {
+		cfg.KafkaRetryMax = *o.KafkaRetryMax
+	}
+
+	if o.KafkaProduceMode != nil {
+		cfg.KafkaProduceMode = *o.KafkaProduceMode
+	}
+	if o.KafkaMaxBufferedRecords != nil {
+		cfg.KafkaMaxBufferedRecords = *o.KafkaMaxBufferedRecords
+	}
+	if o.KafkaBufferFullPolicy != nil {
+		cfg.KafkaBufferFullPolicy = *o.KafkaBufferFullPolicy
+	}
+	if o.KafkaProduceTimeout != nil {
+		d, err := time.ParseDuration(*o.KafkaProduceTimeout)
+		if err != nil {
+			return fmt.Errorf("kafka_produce_timeout: %w", err)
+		}
+		cfg.KafkaProduceTimeout = d
+	}
+	if o.KafkaCompression != nil {
+		cfg.KafkaCompression = *o.KafkaCompression
+	}
+	if o.KafkaAcks != nil {
+		cfg.KafkaAcks = *o.KafkaAcks
+	}
+	if o.KafkaRequiredAcksTimeout != nil {
+		d, err := time.ParseDuration(*o.KafkaRequiredAcksTimeout)
+		if err != nil {
+			return fmt.Errorf("kafka_required_acks_timeout: %w", err)
+		}
+		cfg.KafkaRequiredAcksTimeout = d
+	}
+
+	if o.KafkaTLSEnable != nil {
+		cfg.KafkaTLSEnable = *o.KafkaTLSEnable
+	}
+	if o.KafkaCACert != nil {
+		cfg.KafkaCACert = *o.KafkaCACert
+	}
+	if o.KafkaClientCert != nil {
+		cfg.KafkaClientCert = *o.KafkaClientCert
+	}
+	if o.KafkaClientKey != nil {
+		cfg.KafkaClientKey = *o.KafkaClientKey
+	}
+	if o.KafkaInsecureSkipVerify != nil {
+		cfg.KafkaInsecureSkipVerify = *o.KafkaInsecureSkipVerify
+	}
+	if o.KafkaSASLMechanism != nil {
+		cfg.KafkaSASLMechanism = *o.KafkaSASLMechanism
+	}
+	if o.KafkaSASLUser != nil {
+		cfg.KafkaSASLUser = *o.KafkaSASLUser
+	}
+	if o.KafkaSASLPassword != nil {
+		cfg.KafkaSASLPassword = *o.KafkaSASLPassword
+	}
+
+	if o.RabbitMQURI != nil {
+		cfg.RabbitMQURI = *o.RabbitMQURI
+	}
+	if o.RabbitMQExchange != nil {
+		cfg.RabbitMQExchange = *o.RabbitMQExchange
+	}
+	if o.RabbitMQRoutingKey != nil {
+		cfg.RabbitMQRoutingKey = *o.RabbitMQRoutingKey
+	}
+	if o.RabbitMQQueue != nil {
+		cfg.RabbitMQQueue = *o.RabbitMQQueue
+	}
+
+	if o.RedisHost != nil {
+		cfg.RedisHost = *o.RedisHost
+	}
+	if o.RedisPort != nil {
+		cfg.RedisPort = *o.RedisPort
+	}
+	if o.RedisPassword != nil {
+		cfg.RedisPassword = *o.RedisPassword
+	}
+	if o.RedisDB != nil {
+		cfg.RedisDB = *o.RedisDB
+	}
+	if o.RedisTimeout != nil {
+		d, err := time.ParseDuration(*o.RedisTimeout)
+		if err != nil {
+			return fmt.Errorf("redis_timeout: %w", err)
+		}
+		cfg.RedisTimeout = d
+	}
+	if o.RedisRetryMax != nil {
+		cfg.RedisRetryMax = *o.RedisRetryMax
+	}
+	if o.RedisMode != nil {
+		cfg.RedisMode = *o.RedisMode
+	}
+	if o.RedisClusterAddrs != nil {
+		cfg.RedisClusterAddrs = *o.RedisClusterAddrs
+	}
+	if o.RedisSentinelAddrs != nil {
+		cfg.RedisSentinelAddrs = *o.RedisSentinelAddrs
+	}
+	if o.RedisSentinelMasterName != nil {
+		cfg.RedisSentinelMasterName = *o.RedisSentinelMasterName
+	}
+	if o.RedisSentinelPassword != nil {
+		cfg.RedisSentinelPassword = *o.RedisSentinelPassword
+	}
+
+	if o.CacheLRUSize != nil {
+		cfg.CacheLRUSize = *o.CacheLRUSize
+	}
+	if o.CacheTTL != nil {
+		d, err := time.ParseDuration(*o.CacheTTL)
+		if err != nil {
+			return fmt.Errorf("cache_ttl: %w", err)
+		}
+		cfg.CacheTTL = d
+	}
+	if o.RunID != nil {
+		cfg.RunID = *o.RunID
+	}
+
+	if o.StartURLs != nil {
+		cfg.StartURLs = *o.StartURLs
+	}
+	if o.CrawlDepth != nil {
+		cfg.CrawlDepth = *o.CrawlDepth
+	}
+	if o.MaxPages != nil {
+		cfg.MaxPages = *o.MaxPages
+	}
+	if o.URLWhitelist != nil {
+		cfg.URLWhitelist = *o.URLWhitelist
+	}
+	if o.URLBlacklist != nil {
+		cfg.URLBlacklist = *o.URLBlacklist
+	}
+
+	if o.URLQueryAllowlist != nil {
+		cfg.URLQueryAllowlist = *o.URLQueryAllowlist
+	}
+	if o.URLQueryDenylist != nil {
+		cfg.URLQueryDenylist = *o.URLQueryDenylist
+	}
+
+	if o.MaxConcurrency != nil {
+		cfg.MaxConcurrency = *o.MaxConcurrency
+	}
+	if o.RequestTimeout != nil {
+		d, err := time.ParseDuration(*o.RequestTimeout)
+		if err != nil {
+			return fmt.Errorf("request_timeout: %w", err)
+		}
+		cfg.RequestTimeout = d
+	}
+	if o.RequestDelay != nil {
+		d, err := time.ParseDuration(*o.RequestDelay)
+		if err != nil {
+			return fmt.Errorf("request_delay: %w", err)
+		}
+		cfg.RequestDelay = d
+	}
+	if o.MaxContentSize != nil {
+		cfg.MaxContentSize = *o.MaxContentSize
+	}
+
+	if o.LogLevel != nil {
+		cfg.LogLevel = *o.LogLevel
+	}
+	if o.UserAgent != nil {
+		cfg.UserAgent = *o.UserAgent
+	}
+	if o.EnableDebug != nil {
+		cfg.EnableDebug = *o.EnableDebug
+	}
+	if o.MonitorPort != nil {
+		cfg.MonitorPort = *o.MonitorPort
+	}
+
+	if o.EnableProfiling != nil {
+		cfg.EnableProfiling = *o.EnableProfiling
+	}
+
+	if o.ReadinessBacklogThreshold != nil {
+		cfg.ReadinessBacklogThreshold = *o.ReadinessBacklogThreshold
+	}
+
+	if o.OTelServiceName != nil {
+		cfg.OTelServiceName = *o.OTelServiceName
+	}
+	if o.OTelExporterOTLP != nil {
+		cfg.OTelExporterOTLP = *o.OTelExporterOTLP
+	}
+	if o.OTelSampleRatio != nil {
+		cfg.OTelSampleRatio = *o.OTelSampleRatio
+	}
+
+	if o.APITokens != nil {
+		cfg.APITokens = *o.APITokens
+	}
+	if o.APITokenScopes != nil {
+		cfg.APITokenScopes = *o.APITokenScopes
+	}
+
+	if o.DNSMode != nil {
+		cfg.DNSMode = *o.DNSMode
+	}
+	if o.DNSEndpoint != nil {
+		cfg.DNSEndpoint = *o.DNSEndpoint
+	}
+	if o.DNSBootstrapIPs != nil {
+		cfg.DNSBootstrapIPs = *o.DNSBootstrapIPs
+	}
+	if o.DNSCacheTTL != nil {
+		d, err := time.ParseDuration(*o.DNSCacheTTL)
+		if err != nil {
+			return fmt.Errorf("dns_cache_ttl: %w", err)
+		}
+		cfg.DNSCacheTTL = d
+	}
+	if o.AllowPrivateIPs != nil {
+		cfg.AllowPrivateIPs = *o.AllowPrivateIPs
+	}
+	if o.AllowLoopback != nil {
+		cfg.AllowLoopback = *o.AllowLoopback
+	}
+
+	if o.MetricsSinks != nil {
+		cfg.MetricsSinks = *o.MetricsSinks
+	}
+
+	if o.StatsDHost != nil {
+		cfg.StatsDHost = *o.StatsDHost
+	}
+	if o.StatsDPort != nil {
+		cfg.StatsDPort = *o.StatsDPort
+	}
+	if o.StatsDPrefix != nil {
+		cfg.StatsDPrefix = *o.StatsDPrefix
+	}
+	if o.StatsDFlushInterval != nil {
+		d, err := time.ParseDuration(*o.StatsDFlushInterval)
+		if err != nil {
+			return fmt.Errorf("statsd_flush_interval: %w", err)
+		}
+		cfg.StatsDFlushInterval = d
+	}
+
+	if o.WARCEnable != nil {
+		cfg.WARCEnable = *o.WARCEnable
+	}
+	if o.WARCOutputDir != nil {
+		cfg.WARCOutputDir = *o.WARCOutputDir
+	}
+	if o.WARCFilePrefix != nil {
+		cfg.WARCFilePrefix = *o.WARCFilePrefix
+	}
+	if o.WARCMaxFileSizeMB != nil {
+		cfg.WARCMaxFileSizeMB = *o.WARCMaxFileSizeMB
+	}
+
+	return nil
+}
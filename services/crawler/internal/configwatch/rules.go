@@ -0,0 +1,69 @@
+// Package configwatch watches a standalone URL allow/deny rules file and
+// hot-reloads it independently of the broader config.Manager machinery, so
+// a content or security team can retarget a running crawl's domain rules
+// without touching (or having access to) the rest of the crawler's
+// configuration.
+package configwatch
+
+import (
+	// Stdlib
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	// Third-party
+	"gopkg.in/yaml.v3"
+)
+
+// Rules is the on-disk shape of a rules file: just the two slices
+// UrlValidator.UpdateWhitelist/UpdateBlacklist need.
+type Rules struct {
+	Whitelist []string `yaml:"whitelist" json:"whitelist"`
+	Blacklist []string `yaml:"blacklist" json:"blacklist"`
+}
+
+// parseRulesFile reads and decodes path (YAML for .yaml/.yml, JSON for
+// .json; any other extension is an error), mirroring
+// config.parseOverlayFile's format handling.
+func parseRulesFile(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading rules file: %w", err)
+	}
+
+	rules := &Rules{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, rules); err != nil {
+			return nil, fmt.Errorf("parsing YAML rules file: %w", err)
+		}
+	case ".json":
+		if err := json.Unmarshal(data, rules); err != nil {
+			return nil, fmt.Errorf("parsing JSON rules file: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported rules file extension %q (expected .yaml, .yml or .json)", ext)
+	}
+
+	return rules, nil
+}
+
+// diff describes which of Whitelist/Blacklist changed between two Rules, for
+// logging an old-vs-new summary on reload.
+func diff(old, next *Rules) (whitelistChanged, blacklistChanged bool) {
+	return !stringSlicesEqual(old.Whitelist, next.Whitelist), !stringSlicesEqual(old.Blacklist, next.Blacklist)
+}
+
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
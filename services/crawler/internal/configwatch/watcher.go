@@ -0,0 +1,165 @@
+package configwatch
+
+import (
+	// Stdlib
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	// Third-party
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+)
+
+// Watcher watches a rules file for changes via fsnotify and hot-reloads it,
+// independently of config.Manager. A successful reload is swapped into an
+// atomic.Pointer[Rules]; a failed one (missing file, parse error) is logged
+// and discarded, leaving the previous rules in place.
+type Watcher struct {
+	log  *logrus.Logger
+	path string
+
+	current atomic.Pointer[Rules]
+
+	onReload func(result string) // called "success"/"failure" after every reload attempt
+	onChange func(old, next *Rules)
+
+	watcher *fsnotify.Watcher
+
+	done     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// New reads and parses path once up front (failing fast on a missing or
+// malformed rules file, the same way config.InitializeConfig treats
+// ConfigFile) and returns a Watcher seeded with the result. Call Start to
+// begin watching for changes.
+func New(path string, log *logrus.Logger) (*Watcher, error) {
+	rules, err := parseRulesFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{log: log, path: path, done: make(chan struct{})}
+	w.current.Store(rules)
+	return w, nil
+}
+
+// Current returns the most recently loaded Rules.
+func (w *Watcher) Current() *Rules {
+	return w.current.Load()
+}
+
+// OnReload registers a callback invoked with "success" or "failure" after
+// every reload attempt, e.g. to increment a crawler_rules_reloads_total
+// Prometheus counter.
+func (w *Watcher) OnReload(fn func(result string)) {
+	w.onReload = fn
+}
+
+// OnChange registers a callback invoked with the old and new Rules after a
+// successful reload.
+func (w *Watcher) OnChange(fn func(old, next *Rules)) {
+	w.onChange = fn
+}
+
+// Start begins watching w.path for filesystem changes. It returns once the
+// watcher is set up; the actual watching runs in a background goroutine
+// until Stop is called.
+func (w *Watcher) Start() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	// Watch the containing directory rather than the file itself: editors
+	// commonly replace a file via rename-into-place, which fsnotify can't
+	// follow if it's watching the (now-deleted) inode directly - the same
+	// reasoning as config.Manager.Start.
+	if err := watcher.Add(filepath.Dir(w.path)); err != nil {
+		_ = watcher.Close()
+		return err
+	}
+	w.watcher = watcher
+
+	w.wg.Add(1)
+	go w.run()
+	return nil
+}
+
+// Stop halts the watch goroutine and releases the fsnotify watcher.
+func (w *Watcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.done)
+		if w.watcher != nil {
+			_ = w.watcher.Close()
+		}
+	})
+	w.wg.Wait()
+}
+
+// run is the Watcher's background loop, reacting to fsnotify events on the
+// watched rules file until Stop closes w.done.
+func (w *Watcher) run() {
+	defer w.wg.Done()
+
+	target := filepath.Clean(w.path)
+
+	for {
+		select {
+		case <-w.done:
+			return
+
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != target {
+				continue // a sibling file in the same directory changed
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			w.log.WithField("file", event.Name).Info("Rules file changed, reloading URL allow/deny rules")
+			w.reload()
+
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			w.log.WithError(err).Warn("Rules file watcher error")
+		}
+	}
+}
+
+// reload re-parses w.path and, if it succeeds, atomically swaps it in and
+// fires onChange with an old-vs-new diff logged. A failure is logged and
+// leaves Current() untouched.
+func (w *Watcher) reload() {
+	next, err := parseRulesFile(w.path)
+	if err != nil {
+		w.log.WithError(err).Error("Rules file reload failed; keeping previous rules")
+		if w.onReload != nil {
+			w.onReload("failure")
+		}
+		return
+	}
+
+	old := w.current.Swap(next)
+	whitelistChanged, blacklistChanged := diff(old, next)
+	w.log.WithFields(logrus.Fields{
+		"whitelist_changed": whitelistChanged,
+		"blacklist_changed": blacklistChanged,
+		"old_whitelist":     old.Whitelist,
+		"new_whitelist":     next.Whitelist,
+		"old_blacklist":     old.Blacklist,
+		"new_blacklist":     next.Blacklist,
+	}).Info("Rules file reloaded successfully")
+
+	if w.onReload != nil {
+		w.onReload("success")
+	}
+	if w.onChange != nil {
+		w.onChange(old, next)
+	}
+}
@@ -0,0 +1,55 @@
+package crawler
+
+import (
+	// Stdlib
+	"time"
+
+	// Third-party
+	"github.com/hashicorp/golang-lru/v2/expirable"
+
+	// Internal modules
+	"github.com/sneakyhydra/sneakdex/crawler/internal/metrics"
+)
+
+// membershipCache is the bounded, TTL-expiring LRU hot layer in front of
+// Redis for one of the crawler's three URL membership sets (seen, pending,
+// requeued). A Contains miss falls through to Redis in the caller; a
+// subsequent Add writes the result back so the next lookup for the same URL
+// stays in-process. Sized by CacheLRUSize/CacheTTL instead of growing without
+// bound like the sync.Map it replaces.
+type membershipCache struct {
+	set   string // "seen", "pending", or "requeued"; used as the metrics "set" tag
+	lru   *expirable.LRU[string, struct{}]
+	stats *metrics.Metrics
+}
+
+// newMembershipCache creates a membershipCache bounded to size entries, each
+// expiring ttl after it was last added.
+func newMembershipCache(set string, size int, ttl time.Duration, stats *metrics.Metrics) *membershipCache {
+	return &membershipCache{
+		set:   set,
+		lru:   expirable.NewLRU[string, struct{}](size, nil, ttl),
+		stats: stats,
+	}
+}
+
+// Contains reports whether url is present in the local LRU, recording a
+// cache hit or miss. A miss means the caller must fall through to Redis.
+func (mc *membershipCache) Contains(url string) bool {
+	if _, ok := mc.lru.Get(url); ok {
+		mc.stats.IncrementCacheHit(mc.set)
+		return true
+	}
+	mc.stats.IncrementCacheMiss(mc.set)
+	return false
+}
+
+// Add records url as present, e.g. after a Redis hit or a local write.
+func (mc *membershipCache) Add(url string) {
+	mc.lru.Add(url, struct{}{})
+}
+
+// Remove evicts url, e.g. once it's been dequeued from the pending set.
+func (mc *membershipCache) Remove(url string) {
+	mc.lru.Remove(url)
+}
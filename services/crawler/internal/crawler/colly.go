@@ -2,16 +2,41 @@ package crawler
 
 import (
 	// StdLib
-	"path"
+	"context"
+	"net/http"
 	"strings"
 	"sync/atomic"
+	"time"
 
 	// Third-party
 	"github.com/gocolly/colly/v2"
 	"github.com/gocolly/colly/v2/debug"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+
+	// Internal modules
+	"github.com/sneakyhydra/sneakdex/crawler/internal/crawlerrors"
+	"github.com/sneakyhydra/sneakdex/crawler/internal/pipeline"
+	"github.com/sneakyhydra/sneakdex/crawler/internal/retry"
+	"github.com/sneakyhydra/sneakdex/crawler/internal/tracing"
 )
 
+// fetchDuration returns the elapsed time since the request's OnRequest handler
+// stamped it with "fetch_start", for use as the "Fetch" operation observation.
+func fetchDuration(r *colly.Response) time.Duration {
+	if start, ok := r.Request.Ctx.GetAny("fetch_start").(time.Time); ok {
+		return time.Since(start)
+	}
+	return 0
+}
+
+// fetchSpan retrieves the "Fetch" span opened for this request in OnRequest,
+// if tracing produced one.
+func fetchSpan(r *colly.Response) (trace.Span, bool) {
+	span, ok := r.Request.Ctx.GetAny("fetch_span").(trace.Span)
+	return span, ok
+}
+
 // setupCollyCollector initializes a Colly collector with the necessary handlers and configurations.
 func (c *Crawler) setupCollyCollector() *colly.Collector {
 	collector := c.createBaseCollector()
@@ -32,16 +57,21 @@ func (c *Crawler) createBaseCollector() *colly.Collector {
 		colly.DetectCharset(),
 	}
 
-	if len(c.Blacklist) > 0 {
-		options = append(options, colly.DisallowedDomains(c.Blacklist...))
+	if blacklist := c.CurrentBlacklist(); len(blacklist) > 0 {
+		options = append(options, colly.DisallowedDomains(blacklist...))
 	}
-	if len(c.Whitelist) > 0 {
-		options = append(options, colly.AllowedDomains(c.Whitelist...))
+	if whitelist := c.CurrentWhitelist(); len(whitelist) > 0 {
+		options = append(options, colly.AllowedDomains(whitelist...))
 	}
 	if c.Cfg.EnableDebug {
 		options = append(options, colly.Debugger(&debug.LogDebugger{}))
 	}
 
+	// Colly bakes Parallelism/Delay into the collector's internal limiter at
+	// construction time and has no API to mutate an existing LimitRule, so
+	// unlike queue.go's own concurrency check, these don't respond to a
+	// config.Manager hot-reload until the collector (and therefore the
+	// crawler) is restarted.
 	collector := colly.NewCollector(options...)
 	_ = collector.Limit(&colly.LimitRule{
 		DomainGlob:  "*",
@@ -50,16 +80,35 @@ func (c *Crawler) createBaseCollector() *colly.Collector {
 	})
 
 	collector.SetRequestTimeout(c.Cfg.RequestTimeout)
+
+	// SetRequestTimeout already bounds how long any single fetch can run,
+	// but Colly's underlying http.Client.Timeout has no idea about c.Ctx:
+	// without this, a request already in flight when Shutdown cancels c.Ctx
+	// would still run to completion (or its own timeout) instead of
+	// aborting immediately. ctxBoundTransport binds every outbound request
+	// to c.Ctx's lifetime on top of the existing per-request timeout.
+	collector.WithTransport(&ctxBoundTransport{
+		base: http.DefaultTransport,
+		ctx:  c.Ctx,
+	})
+
 	return collector
 }
 
+// ctxBoundTransport wraps an http.RoundTripper so every outbound request is
+// cancelled the moment the given context is done, in addition to whatever
+// deadline the request already carries.
+type ctxBoundTransport struct {
+	base http.RoundTripper
+	ctx  context.Context
+}
+
+func (t *ctxBoundTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.base.RoundTrip(req.WithContext(t.ctx))
+}
+
 // setRequestHandler allows us to apply headers before the request is made.
 func (c *Crawler) setRequestHandler(collector *colly.Collector) {
-	var skipExts = map[string]struct{}{
-		".pdf": {}, ".jpg": {}, ".jpeg": {}, ".png": {}, ".gif": {}, ".css": {}, ".js": {}, ".ico": {},
-		".svg": {}, ".woff": {}, ".ttf": {}, ".mp4": {}, ".mp3": {}, ".zip": {}, ".exe": {},
-	}
-
 	collector.OnRequest(func(r *colly.Request) {
 		select {
 		case <-c.Ctx.Done():
@@ -80,9 +129,8 @@ func (c *Crawler) setRequestHandler(collector *colly.Collector) {
 				return
 			}
 
-			ext := strings.ToLower(path.Ext(r.URL.Path))
-			if _, skip := skipExts[ext]; skip {
-				c.Log.WithFields(logrus.Fields{"url": r.URL.String(), "ext": ext}).Debug("Skipping URL due to file extension")
+			if d := c.Pipeline.PreRequest(r); d.Skip {
+				c.Log.WithFields(logrus.Fields{"url": r.URL.String(), "reason": d.Reason}).Debug("Skipping URL, rejected by content pipeline")
 				r.Abort()
 				return
 			}
@@ -95,6 +143,9 @@ func (c *Crawler) setRequestHandler(collector *colly.Collector) {
 			r.Headers.Set("Keep-Alive", "timeout=30, max=100")
 			r.Headers.Set("Upgrade-Insecure-Requests", "1")
 
+			r.Ctx.Put("fetch_start", time.Now())
+			_, span := tracing.StartOperation(c.Ctx, "Fetch", r.URL.String())
+			r.Ctx.Put("fetch_span", span)
 			c.IncrementInFlightPages()
 			c.Log.WithFields(logrus.Fields{"url": r.URL.String()}).Debug("Visiting URL")
 		}
@@ -136,54 +187,155 @@ func (c *Crawler) setLinkHandler(collector *colly.Collector) {
 				}
 			}
 
-			normalizedURL, valid := c.UrlValidator.IsValidURL(link)
-			if !valid {
-				return
-			}
-
-			visited, err := c.isURLSeen(normalizedURL)
-			if err != nil || visited {
-				return // Skip error logging for performance
-			}
-
 			parentDepthAny := e.Request.Ctx.GetAny("depth")
 			parentDepth, ok := parentDepthAny.(int)
 			if !ok {
 				parentDepth = 1 // fallback if missing
 			}
 
-			c.AddToPending(QueueItem{
-				URL:   normalizedURL,
-				Depth: parentDepth + 1,
-			})
+			c.enqueueDiscoveredLink(link, parentDepth)
 		}
 	})
 }
 
-// setErrorHandler allows us to handle errors gracefully.
+// enqueueDiscoveredLink validates a link found either in a page's DOM
+// (setLinkHandler) or in a pipeline.ArtifactLink (setResponseHandler, for
+// sitemap/feed processors) and adds it to the pending queue one depth below
+// parentDepth, skipping it silently if it's invalid or already seen.
+func (c *Crawler) enqueueDiscoveredLink(rawLink string, parentDepth int) {
+	normalizedURL, valid := c.UrlValidator.IsValidURL(rawLink)
+	if !valid {
+		return
+	}
+
+	visited, err := c.isURLSeen(c.Ctx, normalizedURL)
+	if err != nil || visited {
+		return // Skip error logging for performance
+	}
+
+	c.AddToPending(c.Ctx, QueueItem{
+		URL:   normalizedURL,
+		Depth: parentDepth + 1,
+	})
+}
+
+// classifyFetchError buckets a Colly fetch error into the reason labels
+// IncrementPagesFailedReason exposes on crawler_pages_failed_reason_total.
+// It's plain string matching under the hood - net.Error and url.Error don't
+// reliably unwrap across Colly/http.Transport's own wrapping - but centralizing
+// it here means setErrorHandler has exactly one place that does this instead
+// of ad hoc checks scattered through the log/metric call sites.
+func classifyFetchError(r *colly.Response, err error) string {
+	switch {
+	case strings.Contains(err.Error(), "timeout"):
+		return "timeout"
+	case strings.Contains(err.Error(), "connection refused"):
+		return "refused"
+	case strings.Contains(err.Error(), "no such host"):
+		return "no_host"
+	case r.StatusCode >= 400:
+		return "http_error"
+	default:
+		return "other"
+	}
+}
+
+// scheduleRetry re-queues item after delay, bounded by c.Ctx so a shutdown
+// mid-backoff drops the pending re-add instead of leaking the goroutine. The
+// URL is not lost in that case: nothing on the retry path clears its
+// InFlightItems entry, so Shutdown returns it to the pending queue, and its
+// Redis retry:<url> attempt count is kept (until attemptTTL expires) for a
+// resumed run.
+func (c *Crawler) scheduleRetry(item QueueItem, delay time.Duration) {
+	c.Wg.Add(1)
+	go func() {
+		defer c.Wg.Done()
+		select {
+		case <-time.After(delay):
+			c.AddToPending(c.Ctx, item)
+		case <-c.Ctx.Done():
+		}
+	}()
+}
+
+// retryAfterHeader returns the "Retry-After" response header, or "" if r has
+// no headers at all - OnError can fire before colly ever got a response
+// (e.g. a dial failure), in which case r.Headers is a nil *http.Header.
+func retryAfterHeader(r *colly.Response) string {
+	if r.Headers == nil {
+		return ""
+	}
+	return r.Headers.Get("Retry-After")
+}
+
+// setErrorHandler allows us to handle errors gracefully. Retriable failures
+// (per retry.Classify) are handed to the retry.Manager for attempt tracking
+// and full-jitter backoff instead of being given up on immediately; the
+// per-host circuit breaker also records every failure here so a host with a
+// high failure rate gets fail-fasted at dispatch (see feedCollyFromRedisQueue).
 func (c *Crawler) setErrorHandler(collector *colly.Collector) {
 	collector.OnError(func(r *colly.Response, err error) {
 		defer c.DecrementInFlightPages()
 		defer c.Stats.IncrementPagesFailed()
-		isNetworkError := strings.Contains(err.Error(), "timeout") ||
-			strings.Contains(err.Error(), "connection refused") ||
-			strings.Contains(err.Error(), "no such host")
+		defer c.Stats.ObserveOperation("Fetch", r.Request.URL.Host, fetchDuration(r).Seconds())
+		defer c.Stats.ObservePageFetch(r.Request.URL.Host, r.StatusCode, fetchDuration(r), 0)
+		reason := classifyFetchError(r, err)
+		defer c.Stats.IncrementPagesFailedReason(reason)
+		c.Breaker.RecordResult(r.Request.URL.Host, false)
+
+		url := r.Request.URL.String()
+		retriable, retryAfter := retry.Classify(r.StatusCode, err, retryAfterHeader(r))
+
+		if span, ok := fetchSpan(r); ok {
+			tracing.RecordCrawlError(span, &crawlerrors.CrawlError{
+				URL:       url,
+				Operation: "Fetch",
+				Err:       err,
+				Retry:     retriable,
+				Timestamp: time.Now(),
+			})
+			span.End()
+		}
+		isNetworkError := reason == "timeout" || reason == "refused" || reason == "no_host"
 
 		if !isNetworkError || c.Cfg.EnableDebug {
 			c.Log.WithFields(logrus.Fields{
-				"url":         r.Request.URL.String(),
+				"url":         url,
 				"status_code": r.StatusCode,
 				"error":       err,
 			}).Warn("Request failed")
 		} else {
 			c.Log.WithFields(logrus.Fields{
-				"url":         r.Request.URL.String(),
+				"url":         url,
 				"status_code": r.StatusCode,
 				"error":       err,
 			}).Debug("Suppressed network error")
 		}
 
-		c.MarkVisited(r.Request.URL.String())
+		if retriable {
+			depth := 1
+			if d, ok := r.Request.Ctx.GetAny("depth").(int); ok {
+				depth = d
+			}
+
+			attempt, attErr := c.RetryManager.RecordAttempt(c.Ctx, url)
+			if attErr != nil {
+				c.Log.WithFields(logrus.Fields{"url": url, "error": attErr}).Warn("Failed to record retry attempt, giving up on URL")
+			} else if attempt <= c.RetryManager.MaxAttempts() {
+				delay := c.RetryManager.Backoff(attempt)
+				if retryAfter > delay {
+					delay = retryAfter
+				}
+				c.Log.WithFields(logrus.Fields{"url": url, "attempt": attempt, "delay": delay}).Info("Retriable fetch error, scheduling retry")
+				c.scheduleRetry(QueueItem{URL: url, Depth: depth}, delay)
+				c.Stats.IncrementPagesRequeued()
+				return
+			} else {
+				c.Log.WithFields(logrus.Fields{"url": url, "attempts": attempt}).Warn("Retry attempts exhausted, giving up on URL")
+			}
+		}
+
+		c.MarkVisited(c.Ctx, url)
 	})
 }
 
@@ -191,6 +343,16 @@ func (c *Crawler) setErrorHandler(collector *colly.Collector) {
 func (c *Crawler) setResponseHandler(collector *colly.Collector) {
 	collector.OnResponse(func(r *colly.Response) {
 		defer c.DecrementInFlightPages()
+		defer c.Stats.ObserveOperation("Fetch", r.Request.URL.Host, fetchDuration(r).Seconds())
+		defer c.Stats.ObservePageFetch(r.Request.URL.Host, r.StatusCode, fetchDuration(r), len(r.Body))
+		c.Breaker.RecordResult(r.Request.URL.Host, true)
+		if err := c.RetryManager.Reset(c.Ctx, r.Request.URL.String()); err != nil {
+			c.Log.WithFields(logrus.Fields{"url": r.Request.URL.String(), "error": err}).Debug("Failed to clear retry attempt count")
+		}
+		if span, ok := fetchSpan(r); ok {
+			tracing.RecordCrawlError(span, nil) // successful fetch
+			span.End()
+		}
 		// extract depth from context
 		depthAny := r.Request.Ctx.GetAny("depth")
 		depth, ok := depthAny.(int)
@@ -209,41 +371,83 @@ func (c *Crawler) setResponseHandler(collector *colly.Collector) {
 			}).Debug("Response received")
 		}
 
-		if !strings.Contains(r.Headers.Get("Content-Type"), "text/html") {
-			c.Log.WithFields(logrus.Fields{"url": r.Request.URL.String(), "depth": depth}).Debug("Non-HTML content received, skipping")
-			c.MarkVisited(r.Request.URL.String())
+		processor := c.Pipeline.Select(r)
+		if processor == nil {
+			c.Log.WithFields(logrus.Fields{"url": r.Request.URL.String(), "depth": depth, "content_type": r.Headers.Get("Content-Type")}).Debug("No pipeline processor accepts this content type, skipping")
+			c.MarkVisited(c.Ctx, r.Request.URL.String())
 			r.Request.Abort()
 			return
 		}
 
-		c.Stats.IncrementPagesProcessed()
 		url := r.Request.URL.String()
-		html := string(r.Body)
-
-		// Send the HTML content to Kafka
-		if retry, err := c.sendToKafka(QueueItem{URL: url, Depth: depth}, html); err != nil {
-			if retry {
-				if exists, err := c.isURLRequeued(url); exists {
-					c.Log.WithFields(logrus.Fields{"url": url}).Trace("URL already requeued once. Will be marked as visited")
-					c.RemoveFromRequeued(url)
-				} else {
-					// Re-queue URL instead of marking as visited
-					c.Log.WithFields(logrus.Fields{"url": url, "error": err}).Warn("Retriable error occurred, requeuing URL")
-
-					c.AddToPending(QueueItem{URL: url, Depth: depth})
-					c.AddToRequeued(url)
-					return
-				}
+
+		// Archive the raw request/response to WARC, independent of whether
+		// the queue publish below succeeds - a page that fails to publish
+		// (or gets requeued) is still a page we fetched. Archived verbatim
+		// regardless of which processor handles it.
+		if c.WarcWriter != nil {
+			if err := c.WarcWriter.WritePage(url, r.Request.Method, *r.Request.Headers, nil, r.StatusCode, *r.Headers, r.Body); err != nil {
+				c.Log.WithFields(logrus.Fields{"url": url, "error": err}).Warn("Failed to write WARC record")
 			}
+		}
 
-			c.Log.WithFields(logrus.Fields{"url": url, "error": err}).Error("Failed to send to Kafka")
+		artifacts, err := processor.PostResponse(r)
+		if err != nil {
+			c.Log.WithFields(logrus.Fields{"url": url, "processor": processor.Name(), "error": err}).Error("Pipeline processor failed")
 			c.Stats.IncrementPagesFailed()
-			c.MarkVisited(url)
+			c.Stats.IncrementPagesFailedReason("pipeline")
+			c.MarkVisited(c.Ctx, url)
 			return
 		}
 
+		c.Stats.IncrementPagesProcessed()
+
+		for _, artifact := range artifacts {
+			switch artifact.Kind {
+			case pipeline.ArtifactLink:
+				c.enqueueDiscoveredLink(artifact.URL, depth)
+			case pipeline.ArtifactContent:
+				if c.publishArtifact(QueueItem{URL: url, Depth: depth}, artifact) {
+					c.MarkVisited(c.Ctx, url)
+					return
+				}
+			}
+		}
+
 		c.Stats.IncrementPagesSuccessful()
-		c.Log.WithFields(logrus.Fields{"url": url, "content_size": len(html)}).Debug("Page processed successfully and enqueued to Kafka.")
-		c.MarkVisited(url)
+		c.Log.WithFields(logrus.Fields{"url": url, "processor": processor.Name()}).Debug("Page processed successfully and enqueued to message queue.")
+		c.MarkVisited(c.Ctx, url)
 	})
 }
+
+// publishArtifact hands a pipeline.ArtifactContent off to sendToQueue and
+// applies the same requeue/terminal-failure handling the crawler has always
+// used for a failed publish. It returns true once the URL's outcome has
+// already been handled terminally (requeued or given up on), telling the
+// caller to stop without also marking it successful.
+func (c *Crawler) publishArtifact(item QueueItem, artifact pipeline.Artifact) (handled bool) {
+	retriable, err := c.sendToQueue(item, artifact.Topic, artifact.Body)
+	if err == nil {
+		return false
+	}
+
+	if retriable {
+		if exists, _ := c.isURLRequeued(item.URL); exists {
+			c.Log.WithFields(logrus.Fields{"url": item.URL}).Trace("URL already requeued once. Will be marked as visited")
+			c.RemoveFromRequeued(item.URL)
+		} else {
+			// Re-queue URL instead of marking as visited
+			c.Log.WithFields(logrus.Fields{"url": item.URL, "error": err}).Warn("Retriable error occurred, requeuing URL")
+
+			c.AddToPending(c.Ctx, item)
+			c.AddToRequeued(c.Ctx, item.URL)
+			c.Stats.IncrementPagesRequeued()
+			return true
+		}
+	}
+
+	c.Log.WithFields(logrus.Fields{"url": item.URL, "error": err}).Error("Failed to send to queue")
+	c.Stats.IncrementPagesFailed()
+	c.Stats.IncrementPagesFailedReason("kafka")
+	return true
+}
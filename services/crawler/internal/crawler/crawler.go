@@ -6,38 +6,88 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	// Third-party
-	"github.com/IBM/sarama"
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 
 	// Internal modules
+	"github.com/sneakyhydra/sneakdex/crawler/internal/checkpoint"
+	"github.com/sneakyhydra/sneakdex/crawler/internal/circuitbreaker"
 	"github.com/sneakyhydra/sneakdex/crawler/internal/config"
+	"github.com/sneakyhydra/sneakdex/crawler/internal/configwatch"
+	"github.com/sneakyhydra/sneakdex/crawler/internal/logger"
 	"github.com/sneakyhydra/sneakdex/crawler/internal/metrics"
+	"github.com/sneakyhydra/sneakdex/crawler/internal/pipeline"
+	"github.com/sneakyhydra/sneakdex/crawler/internal/queue"
+	"github.com/sneakyhydra/sneakdex/crawler/internal/retry"
+	"github.com/sneakyhydra/sneakdex/crawler/internal/tracing"
+	"github.com/sneakyhydra/sneakdex/crawler/internal/utils"
 	"github.com/sneakyhydra/sneakdex/crawler/internal/validator"
+	"github.com/sneakyhydra/sneakdex/crawler/internal/warc"
 )
 
 // Crawler represents the main web crawler instance. It manages the lifecycle
-// of crawling operations, including Redis queue interaction, Kafka publishing,
-// and Colly collector integration.
+// of crawling operations, including Redis queue interaction, message queue
+// publishing, and Colly collector integration.
 type Crawler struct {
-	Cfg *config.Config // Application configuration, loaded from config package.
-	Log *logrus.Logger // Logger instance for structured logging throughout the crawler's operations.
-
-	RedisClient   *redis.Client           // Client for interacting with Redis for URL queue management.
-	AsyncProducer sarama.AsyncProducer    // Kafka async producer for publishing crawled page data.
-	Stats         *metrics.Metrics        // Metrics collector for tracking crawling statistics.
+	Cfg          *config.Config       // Application configuration, loaded from config package.
+	CfgManager   *config.Manager      // Hot-reload manager wrapping Cfg; see config.Manager for which fields it actually affects live.
+	RulesWatcher *configwatch.Watcher // Optional standalone whitelist/blacklist rules-file watcher, nil unless Cfg.RulesFile is set.
+	Log          *logrus.Logger       // Logger instance for structured logging throughout the crawler's operations.
+
+	RedisClient  redis.UniversalClient   // Client for interacting with Redis for URL queue management (standalone, Sentinel, or Cluster; see RedisMode).
+	Publisher    queue.Publisher         // Message queue backend (Kafka or RabbitMQ) for publishing crawled page data.
+	WarcWriter   *warc.Writer            // Optional local WARC archival sink, nil unless Cfg.WARCEnable is set.
+	Stats        *metrics.Metrics        // Metrics collector for tracking crawling statistics.
 	UrlValidator *validator.URLValidator // URL validator for checking and normalizing URLs.
-
-	Whitelist     []string // List of URL patterns allowed for crawling.
-	Blacklist     []string // List of URL patterns disallowed for crawling.
-	Visited       sync.Map // A concurrent map to keep track of URLs that have been visited or are currently in flight
-	Requeued      sync.Map // A concurrent map to keep track of URLs that have been re-queued due to transient errors.
-	Pending       sync.Map // Local cache for pending URLs to avoid Redis checks
-	SeenLocal     sync.Map // Local cache for any URL we've seen (visited, pending, or rejected)
-	InFlightPages int64    // Track pages currently being processed (consider using a semaphore or channel for more robust control if this becomes complex).
+	Pipeline     *pipeline.Pipeline      // Ordered content-type Filters/Processors; see internal/pipeline and buildPipeline.
+
+	RetryManager *retry.Manager          // Per-URL attempt tracking and backoff for retriable fetch/send failures; see internal/retry.
+	Breaker      *circuitbreaker.Breaker // Per-host failure-rate circuit breaker guarding both Colly dispatch and the retry path; see internal/circuitbreaker.
+	Checkpoint   *checkpoint.Manager     // Periodic snapshot/restore of stats counters under RunID; see internal/checkpoint.
+
+	tracerShutdown tracing.ShutdownFunc // Flushes and closes the OpenTelemetry tracer provider on shutdown.
+
+	// Whitelist and Blacklist hold the URL patterns used at Colly collector
+	// construction. They're atomic.Pointer-backed rather than plain slices
+	// because applyConfigChange can swap them in from the config.Manager's
+	// reload goroutine at any time; CurrentWhitelist/CurrentBlacklist give
+	// readers a consistent snapshot without a separate lock.
+	Whitelist atomic.Pointer[[]string]
+	Blacklist atomic.Pointer[[]string]
+
+	// RunID namespaces every Redis key this crawl touches (see storage.go's
+	// pendingURLsSetKey/getQueueKey/visitedKey/requeuedURLsKey), resolved once
+	// in New() from Cfg.RunID: a freshly generated UUID for a new run, or the
+	// configured value to resume a previous one.
+	RunID string
+
+	// SeenCache, PendingCache, and RequeuedCache are the bounded, TTL-expiring
+	// LRU hot layer in front of Redis for the seen/pending/requeued URL
+	// membership sets, sized by CacheLRUSize/CacheTTL. A miss falls through
+	// to Redis; a Redis hit is written back so the next lookup stays local.
+	SeenCache     *membershipCache
+	PendingCache  *membershipCache
+	RequeuedCache *membershipCache
+
+	// InFlightItems tracks URLs that have been popped from the Redis pending
+	// queue and handed to Colly but not yet resolved (MarkVisited or
+	// AddToRequeued). Shutdown drains it back into the pending queue so a
+	// process that dies mid-crawl doesn't lose the URLs it was actively
+	// working on.
+	InFlightItems sync.Map // map[string]QueueItem
+
+	InFlightPages int64 // Track pages currently being processed (consider using a semaphore or channel for more robust control if this becomes complex).
+
+	// FeederLastTick is the Unix-nano timestamp of feedCollyFromRedisQueue's
+	// most recent loop iteration, updated via atomic.StoreInt64. The
+	// monitor package's readiness "feeder alive" check compares it against
+	// time.Now to detect a wedged or exited feeder goroutine.
+	FeederLastTick int64
 
 	Ctx           context.Context
 	CtxCancel     context.CancelFunc // Function to cancel the context, used for graceful shutdown.
@@ -56,26 +106,63 @@ func New(cfg *config.Config, log *logrus.Logger) (*Crawler, error) {
 	ctx, ctxCancel := context.WithCancel(context.Background())
 	shutdown := make(chan struct{})
 
+	// Initialize the metrics collector, fanning out to the Sink(s) named by
+	// cfg.MetricsSinks (Prometheus, StatsD, ...).
+	stats, err := metrics.NewMetrics(cfg)
+	if err != nil {
+		ctxCancel()
+		return nil, fmt.Errorf("failed to initialize metrics: %w", err)
+	}
+
+	// Resolve the run ID that namespaces every Redis key this crawl touches.
+	// An empty RUN_ID starts a fresh run under a freshly generated ID; a
+	// non-empty one resumes a previous run's persistent queue/visited/
+	// requeued state exactly where it left off.
+	runID := cfg.RunID
+	freshRun := runID == ""
+	if freshRun {
+		runID = uuid.NewString()
+	}
+	log.WithFields(logrus.Fields{"run_id": runID, "fresh": freshRun}).Info("Resolved crawl run ID")
+
 	// Initialize the Crawler instance.
 	crawler := &Crawler{
-		Cfg:       cfg,
-		Log:       log,
-		Stats:     metrics.NewMetrics(), // Create a new metrics collector.
-		Ctx:       ctx,
-		CtxCancel: ctxCancel,
-		CShutdown: shutdown,
+		Cfg:            cfg,
+		Log:            log,
+		Stats:          stats,
+		Ctx:            ctx,
+		CtxCancel:      ctxCancel,
+		CShutdown:      shutdown,
+		FeederLastTick: time.Now().UnixNano(),
+		RunID:          runID,
+		SeenCache:      newMembershipCache("seen", cfg.CacheLRUSize, cfg.CacheTTL, stats),
+		PendingCache:   newMembershipCache("pending", cfg.CacheLRUSize, cfg.CacheTTL, stats),
+		RequeuedCache:  newMembershipCache("requeued", cfg.CacheLRUSize, cfg.CacheTTL, stats),
 	}
 
 	// Parse URL whitelist and blacklist from configuration.
 	// These are used by the URL validator to filter URLs.
-	if cfgURLWhitelist := cfg.URLWhitelist; cfgURLWhitelist != "" {
-		crawler.Whitelist = strings.Split(cfgURLWhitelist, ",")
-		log.Debugf("URL Whitelist configured: %s", cfgURLWhitelist)
+	whitelist := splitCommaList(cfg.URLWhitelist)
+	blacklist := splitCommaList(cfg.URLBlacklist)
+	crawler.Whitelist.Store(&whitelist)
+	crawler.Blacklist.Store(&blacklist)
+	if len(whitelist) > 0 {
+		log.Debugf("URL Whitelist configured: %s", cfg.URLWhitelist)
+	}
+	if len(blacklist) > 0 {
+		log.Debugf("URL Blacklist configured: %s", cfg.URLBlacklist)
 	}
-	if cfgURLBlacklist := cfg.URLBlacklist; cfgURLBlacklist != "" {
-		crawler.Blacklist = strings.Split(cfgURLBlacklist, ",")
-		log.Debugf("URL Blacklist configured: %s", cfgURLBlacklist)
+
+	// Initialize OpenTelemetry tracing. Spans are created unconditionally (and
+	// cheaply dropped by the sampler) even when no OTLP endpoint is configured,
+	// so pipeline code never needs to branch on whether tracing is enabled.
+	tracerShutdown, err := tracing.Init(ctx, cfg)
+	if err != nil {
+		ctxCancel()
+		return nil, fmt.Errorf("failed to initialize tracing: %w", err)
 	}
+	crawler.tracerShutdown = tracerShutdown
+	log.Info("OpenTelemetry tracing initialized successfully.")
 
 	// Initialize Redis client.
 	if err := crawler.initializeRedis(); err != nil {
@@ -84,30 +171,216 @@ func New(cfg *config.Config, log *logrus.Logger) (*Crawler, error) {
 	}
 	log.Info("Redis client initialized successfully.")
 
-	// Initialize Kafka producer. This is used to send crawled page data to Kafka.
-	// This will now initialize an AsyncProducer and start its handlers.
-	if err := crawler.initializeKafka(); err != nil {
+	// Initialize per-URL retry attempt tracking and the per-host circuit
+	// breaker that guards both the initial dispatch and the retry path.
+	crawler.RetryManager = retry.NewManager(crawler.RedisClient, runID, cfg.RetryMaxAttempts, cfg.RetryBaseDelay, cfg.RetryMaxDelay)
+	crawler.Breaker = circuitbreaker.New(cfg.CircuitBreakerFailureThreshold, cfg.CircuitBreakerWindow, cfg.CircuitBreakerMinRequests, cfg.CircuitBreakerCooldown)
+	crawler.Checkpoint = checkpoint.NewManager(crawler.RedisClient, runID)
+
+	// A resumed run (RunID set to a previous run's ID) either restores its
+	// last checkpointed stats counters, or - if Resume is explicitly false -
+	// has its prior state purged so it starts genuinely fresh under the
+	// same ID. A freshly generated RunID never has prior state either way.
+	if !freshRun {
+		resumeCtx, resumeCancel := context.WithTimeout(ctx, cfg.RedisTimeout)
+		if !cfg.Resume {
+			log.WithField("run_id", runID).Info("Resume disabled: purging prior state for this run ID before starting")
+			if err := crawler.purgeRunState(resumeCtx); err != nil {
+				log.WithError(err).Warn("Failed to purge prior run state; some stale URLs or counters may carry over")
+			}
+		} else if snap, err := crawler.Checkpoint.Load(resumeCtx); err != nil {
+			log.WithError(err).Warn("Failed to load crawl checkpoint; resuming with zeroed stats counters")
+		} else if snap != nil {
+			if snap.StartURLs != cfg.StartURLs || snap.CrawlDepth != cfg.CrawlDepth {
+				log.WithFields(logrus.Fields{
+					"checkpoint_start_urls":  snap.StartURLs,
+					"configured_start_urls":  cfg.StartURLs,
+					"checkpoint_crawl_depth": snap.CrawlDepth,
+					"configured_crawl_depth": cfg.CrawlDepth,
+				}).Warn("Resuming run with different seed URLs or crawl depth than its last checkpoint")
+			}
+			stats.RestoreCounters(snap.PagesProcessed, snap.PagesSuccessful, snap.PagesFailed, snap.PagesRequeued,
+				snap.KafkaSuccessful, snap.KafkaFailed, snap.KafkaErrored,
+				snap.RedisSuccessful, snap.RedisFailed, snap.RedisErrored)
+			log.WithField("saved_at", snap.SavedAt).Info("Restored crawl stats counters from checkpoint")
+		}
+		resumeCancel()
+	}
+
+	// Initialize the configured message queue backend (Kafka or RabbitMQ) used
+	// to hand off crawled page data to the parser.
+	publisher, err := queue.New(cfg, log, crawler.Stats)
+	if err != nil {
 		ctxCancel() // Trigger context cancellation on failure.
-		return nil, fmt.Errorf("failed to initialize Kafka producer: %w", err)
+		return nil, fmt.Errorf("failed to initialize %s queue publisher: %w", cfg.QueueBackend, err)
+	}
+	crawler.Publisher = publisher
+
+	// Kafka's async producer only reports delivery success/failure after the
+	// fact; hook those callbacks up to crawler stats and requeue logic.
+	if asyncSetter, ok := crawler.Publisher.(queue.AsyncCallbackSetter); ok {
+		asyncSetter.SetCallbacks(crawler.handlePublishSuccess, crawler.handlePublishError)
+	}
+	log.Infof("%s queue publisher initialized successfully.", cfg.QueueBackend)
+
+	// Initialize the optional local WARC archival sink. Runs alongside the
+	// queue publisher above, not instead of it - WritePage is called
+	// independently of Publish in colly.go.
+	warcWriter, err := warc.New(cfg, log, crawler.Stats)
+	if err != nil {
+		ctxCancel()
+		return nil, fmt.Errorf("failed to initialize WARC writer: %w", err)
+	}
+	crawler.WarcWriter = warcWriter
+	if warcWriter != nil {
+		log.WithField("output_dir", cfg.WARCOutputDir).Info("WARC archival sink initialized successfully.")
 	}
-	log.Info("Kafka AsyncProducer initialized successfully.")
 
 	// Initialize and configure the URL Validator.
 	// This component is responsible for checking the validity and safety of URLs.
-	crawler.UrlValidator = validator.NewURLValidator(crawler.Whitelist, crawler.Blacklist, log)
+	crawler.UrlValidator = validator.NewURLValidator(whitelist, blacklist, log)
 
 	// Configure URL Validator settings. These settings impact performance and security.
-	crawler.UrlValidator.SetDNSCacheTimeout(10 * time.Minute)
+	crawler.UrlValidator.SetDNSCacheTimeout(cfg.DNSCacheTTL)
 	// SetSkipDNSCheck to 'false' means DNS resolution will be performed for each URL.
 	// Setting it to 'true' would skip DNS checks, which is faster but less safe
 	// as it could allow connections to unresolvable or malicious IPs.
 	crawler.UrlValidator.SetSkipDNSCheck(true)
-	crawler.UrlValidator.SetAllowPrivateIPs(false) // Disallow crawling of private IP addresses.
-	crawler.UrlValidator.SetAllowLoopback(false)   // Disallow crawling of loopback addresses (e.g., 127.0.0.1).
+	crawler.UrlValidator.SetAllowPrivateIPs(cfg.AllowPrivateIPs)
+	crawler.UrlValidator.SetAllowLoopback(cfg.AllowLoopback)
+	crawler.UrlValidator.SetCanonicalizer(utils.NewURLCanonicalizer(cfg.URLQueryAllowlist, cfg.URLQueryDenylist))
+
+	resolver, err := validator.NewResolverFromConfig(cfg.DNSMode, cfg.DNSEndpoint, cfg.DNSBootstrapIPs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize DNS resolver: %w", err)
+	}
+	crawler.UrlValidator.SetResolver(resolver)
+
+	// Build the content-type pipeline that setRequestHandler/setResponseHandler
+	// dispatch through in place of their old hard-coded extension/Content-Type
+	// checks. See internal/pipeline and buildPipeline.
+	crawler.Pipeline = crawler.buildPipeline()
+
+	// Wire up config hot-reload: CONFIG_FILE (if set) and SIGHUP both
+	// re-validate and swap in a new Config. Only a handful of fields
+	// actually propagate anywhere once swapped in - see config.Manager's
+	// doc comment for the live-reloadable/restart-required split.
+	crawler.CfgManager = config.NewManager(cfg, log)
+	crawler.CfgManager.OnReload(crawler.Stats.IncrementConfigReload)
+	crawler.CfgManager.OnChange(crawler.applyConfigChange)
+	if err := crawler.CfgManager.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start config hot-reload manager: %w", err)
+	}
+
+	// Wire up the optional standalone rules-file watcher: unlike
+	// CfgManager, this lets a content/security team retarget the crawl's
+	// domain allow/deny rules via a small dedicated file, independently of
+	// the broader crawler config.
+	if cfg.RulesFile != "" {
+		watcher, err := configwatch.New(cfg.RulesFile, log)
+		if err != nil {
+			ctxCancel()
+			return nil, fmt.Errorf("failed to load rules file %q: %w", cfg.RulesFile, err)
+		}
+		crawler.RulesWatcher = watcher
+		crawler.applyRulesChange(nil, watcher.Current())
+		crawler.RulesWatcher.OnReload(crawler.Stats.IncrementRulesReload)
+		crawler.RulesWatcher.OnChange(crawler.applyRulesChange)
+		if err := crawler.RulesWatcher.Start(); err != nil {
+			return nil, fmt.Errorf("failed to start rules file watcher: %w", err)
+		}
+		log.WithField("rules_file", cfg.RulesFile).Info("Rules file watcher started")
+	}
 
 	return crawler, nil
 }
 
+// applyRulesChange pushes a configwatch.Watcher reload's new Whitelist/
+// Blacklist into the same places applyConfigChange does, so the crawl's
+// domain rules can be managed from either ConfigFile or RulesFile. old is
+// nil on the initial load from New().
+func (c *Crawler) applyRulesChange(old, next *configwatch.Rules) {
+	whitelist := next.Whitelist
+	blacklist := next.Blacklist
+	c.Whitelist.Store(&whitelist)
+	c.Blacklist.Store(&blacklist)
+	c.UrlValidator.UpdateWhitelist(whitelist)
+	c.UrlValidator.UpdateBlacklist(blacklist)
+
+	if old != nil {
+		c.Log.WithFields(logrus.Fields{
+			"whitelist": whitelist,
+			"blacklist": blacklist,
+		}).Info("Rules file reload: applied new URL whitelist/blacklist")
+	}
+}
+
+// applyConfigChange pushes the handful of fields config.Manager can
+// actually change at runtime into the subsystems that care, after a
+// successful hot-reload swapped next in as the live configuration. If
+// Cfg.RulesFile is also set, whichever of this and applyRulesChange fires
+// last wins Whitelist/Blacklist - running both against the same crawl is
+// unusual but not prevented.
+func (c *Crawler) applyConfigChange(old, next *config.Config) {
+	if next.LogLevel != old.LogLevel {
+		if err := logger.SetLevel(c.Log, next.LogLevel); err != nil {
+			c.Log.WithError(err).Warn("Config reload: failed to apply new log level")
+		} else {
+			c.Log.WithField("log_level", next.LogLevel).Info("Config reload: applied new log level")
+		}
+	}
+
+	if next.URLWhitelist != old.URLWhitelist {
+		whitelist := splitCommaList(next.URLWhitelist)
+		c.Whitelist.Store(&whitelist)
+		c.UrlValidator.UpdateWhitelist(whitelist) // also clears the validator's domain cache
+		c.Log.WithField("url_whitelist", next.URLWhitelist).Info("Config reload: applied new URL whitelist")
+	}
+	if next.URLBlacklist != old.URLBlacklist {
+		blacklist := splitCommaList(next.URLBlacklist)
+		c.Blacklist.Store(&blacklist)
+		c.UrlValidator.UpdateBlacklist(blacklist) // also clears the validator's domain cache
+		c.Log.WithField("url_blacklist", next.URLBlacklist).Info("Config reload: applied new URL blacklist")
+	}
+
+	if next.CrawlDepth != old.CrawlDepth {
+		c.Log.WithField("crawl_depth", next.CrawlDepth).Info("Config reload: applied new crawl depth (queue depth checks only; Colly's own MaxDepth limiter still needs a restart)")
+	}
+
+	if next.DNSCacheTTL != old.DNSCacheTTL {
+		c.UrlValidator.SetDNSCacheTimeout(next.DNSCacheTTL)
+		c.Log.WithField("dns_cache_ttl", next.DNSCacheTTL).Info("Config reload: applied new DNS cache TTL")
+	}
+	if next.AllowPrivateIPs != old.AllowPrivateIPs {
+		c.UrlValidator.SetAllowPrivateIPs(next.AllowPrivateIPs)
+		c.Log.WithField("allow_private_ips", next.AllowPrivateIPs).Info("Config reload: applied new allow-private-IPs setting")
+	}
+	if next.AllowLoopback != old.AllowLoopback {
+		c.UrlValidator.SetAllowLoopback(next.AllowLoopback)
+		c.Log.WithField("allow_loopback", next.AllowLoopback).Info("Config reload: applied new allow-loopback setting")
+	}
+}
+
+// CurrentWhitelist returns the most recently applied URL whitelist.
+func (c *Crawler) CurrentWhitelist() []string {
+	return *c.Whitelist.Load()
+}
+
+// CurrentBlacklist returns the most recently applied URL blacklist.
+func (c *Crawler) CurrentBlacklist() []string {
+	return *c.Blacklist.Load()
+}
+
+// splitCommaList splits a comma-separated config value into a slice,
+// matching how Whitelist/Blacklist are parsed at startup (an empty string
+// yields a nil slice, not [""]).
+func splitCommaList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
 // Start initiates the web crawling process.
 // It sets up the Colly collector, seeds the Redis queue with start URLs,
 // and orchestrates the goroutines responsible for feeding URLs to Colly
@@ -119,11 +392,11 @@ func (c *Crawler) Start() error {
 
 	// Log the full crawler configuration for debugging and operational visibility.
 	c.Log.WithFields(logrus.Fields{
-		"kafka_config": logrus.Fields{
-			"brokers":       c.Cfg.KafkaBrokers,
-			"topic":         c.Cfg.KafkaTopic,
-			"retry_max":     c.Cfg.KafkaRetryMax,
-			"producer_type": "Async", // Explicitly state producer type
+		"queue_config": logrus.Fields{
+			"backend":   c.Cfg.QueueBackend,
+			"brokers":   c.Cfg.KafkaBrokers,
+			"topic":     c.Cfg.KafkaTopic,
+			"retry_max": c.Cfg.KafkaRetryMax,
 		},
 		"redis_config": logrus.Fields{
 			"host":      c.Cfg.RedisHost,
@@ -167,10 +440,7 @@ func (c *Crawler) Start() error {
 		c.Log.Warn("No start URLs provided in configuration. Crawler will not initiate any crawls.")
 	} else {
 		c.Log.Infof("Attempting to seed Redis with %d start URLs.", len(startURLs))
-		
-		// Pre-populate local cache by loading existing Redis data
-		c.preloadLocalCaches()
-		
+
 		for _, rawURL := range startURLs {
 			url := strings.TrimSpace(rawURL)
 			if url == "" {
@@ -184,15 +454,16 @@ func (c *Crawler) Start() error {
 				continue
 			}
 
-			c.AddToPending(normalizedURL)
+			c.AddToPending(c.Ctx, QueueItem{URL: normalizedURL, Depth: 0})
 		}
 	}
 
 	// --- Core Waiting Logic ---
 	// This section manages the lifetime of the main crawling operations.
 
-	// Increment WaitGroup for the feedCollyFromRedisQueue goroutine.
-	// This ensures the main goroutine waits for it to complete.
+	// Increment WaitGroup for the feedCollyFromRedisQueue goroutine. This
+	// ensures the main goroutine waits for it to complete via c.Wg.Wait()
+	// below.
 	c.Wg.Add(1)
 	go c.feedCollyFromRedisQueue(collector)
 
@@ -264,13 +535,40 @@ func (c *Crawler) Shutdown() {
 			c.Log.Warn("Timeout waiting for background goroutines to finish. Some might still be running or blocked.")
 		}
 
-		// Close Kafka producer connection.
-		if c.AsyncProducer != nil {
-			c.Log.Info("Attempting to close Kafka AsyncProducer.")
-			if err := c.AsyncProducer.Close(); err != nil {
-				c.Log.WithError(err).Error("Failed to close Kafka AsyncProducer.")
+		// Return any URLs that were popped from the pending queue and handed
+		// to Colly but never resolved (MarkVisited/AddToRequeued) back to the
+		// pending queue, so a process that dies mid-crawl doesn't lose them.
+		// c.Ctx is already cancelled above, so a fresh bounded context is used.
+		recoverCtx, recoverCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		recovered := 0
+		c.InFlightItems.Range(func(key, value any) bool {
+			item, ok := value.(QueueItem)
+			if ok {
+				c.AddToPending(recoverCtx, item)
+				recovered++
+			}
+			c.InFlightItems.Delete(key)
+			return true
+		})
+		recoverCancel()
+		if recovered > 0 {
+			c.Log.WithField("count", recovered).Info("Returned in-flight URLs to the pending queue on shutdown")
+		}
+
+		// Save a final checkpoint while Redis is still reachable, so a
+		// restart under this same RunID resumes from the freshest possible
+		// stats counters rather than the last periodic tick.
+		if c.Checkpoint != nil {
+			c.saveCheckpoint()
+		}
+
+		// Close the queue publisher connection.
+		if c.Publisher != nil {
+			c.Log.Info("Attempting to close queue publisher.")
+			if err := c.Publisher.Close(); err != nil {
+				c.Log.WithError(err).Error("Failed to close queue publisher.")
 			} else {
-				c.Log.Info("Kafka AsyncProducer closed successfully.")
+				c.Log.Info("Queue publisher closed successfully.")
 			}
 		}
 
@@ -284,10 +582,101 @@ func (c *Crawler) Shutdown() {
 			}
 		}
 
+		// Stop watching for config hot-reloads.
+		if c.CfgManager != nil {
+			c.CfgManager.Stop()
+		}
+
+		// Stop watching the standalone rules file, if configured.
+		if c.RulesWatcher != nil {
+			c.RulesWatcher.Stop()
+		}
+
+		// Flush and close the WARC archival sink, if enabled.
+		if c.WarcWriter != nil {
+			c.Log.Info("Attempting to close WARC writer.")
+			if err := c.WarcWriter.Close(); err != nil {
+				c.Log.WithError(err).Error("Failed to close WARC writer.")
+			} else {
+				c.Log.Info("WARC writer closed successfully.")
+			}
+		}
+
+		// Flush and release metrics sink resources (e.g. the StatsD sink's
+		// background flush loop and UDP socket).
+		if c.Stats != nil {
+			if err := c.Stats.Close(); err != nil {
+				c.Log.WithError(err).Warn("Failed to cleanly close metrics sinks")
+			}
+		}
+
+		// Flush and close the OpenTelemetry tracer provider so in-flight spans
+		// aren't lost on exit.
+		if c.tracerShutdown != nil {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := c.tracerShutdown(shutdownCtx); err != nil {
+				c.Log.WithError(err).Error("Failed to shut down tracer provider.")
+			} else {
+				c.Log.Info("Tracer provider shut down successfully.")
+			}
+		}
+
 		c.Log.Info("Crawler shutdown complete.")
 	})
 }
 
+// reportQueueDepths sets crawler_queue_depth{queue=...} for each of the
+// "pending" (summed across depth levels), "requeued", and "visited" queues,
+// so operators can watch the frontier grow and drain without scraping Redis
+// directly. It's polled on the same 10-second tick as logMetricsPeriodically
+// rather than on every enqueue/dequeue, since none of the three call sites
+// need per-operation precision.
+func (c *Crawler) reportQueueDepths() {
+	var pending int64
+	for _, n := range c.GetQueueStats() {
+		pending += n
+	}
+	c.Stats.SetQueueDepth("pending", pending)
+
+	if requeued, err := c.GetRequeuedCount(); err != nil {
+		c.Log.WithError(err).Warn("Failed to get requeued queue depth")
+	} else {
+		c.Stats.SetQueueDepth("requeued", requeued)
+	}
+
+	c.Stats.SetQueueDepth("visited", c.Stats.GetVisitedMarked())
+}
+
+// saveCheckpoint snapshots the crawl's stats counters and seed
+// configuration to Redis via c.Checkpoint, so a pod restart under the same
+// RunID restores running totals instead of silently resetting them to
+// zero. It's called on the same 10-second tick as reportQueueDepths, and
+// once more, best-effort, during Shutdown.
+func (c *Crawler) saveCheckpoint() {
+	ctx, cancel := context.WithTimeout(context.Background(), c.Cfg.RedisTimeout)
+	defer cancel()
+
+	stats := c.Stats.GetStats()
+	snap := checkpoint.Snapshot{
+		StartURLs:       c.Cfg.StartURLs,
+		CrawlDepth:      c.CfgManager.Current().CrawlDepth,
+		PagesProcessed:  stats["pages_processed"].(int64),
+		PagesSuccessful: stats["pages_successful"].(int64),
+		PagesFailed:     stats["pages_failed"].(int64),
+		PagesRequeued:   stats["pages_requeued"].(int64),
+		KafkaSuccessful: stats["kafka_successful"].(int64),
+		KafkaFailed:     stats["kafka_failed"].(int64),
+		KafkaErrored:    stats["kafka_errored"].(int64),
+		RedisSuccessful: stats["redis_successful"].(int64),
+		RedisFailed:     stats["redis_failed"].(int64),
+		RedisErrored:    stats["redis_errored"].(int64),
+	}
+	if err := c.Checkpoint.Save(ctx, snap); err != nil {
+		c.Log.WithError(err).Warn("Failed to save crawl checkpoint")
+	}
+}
+
 // logMetricsPeriodically starts a goroutine that logs crawler metrics
 // to the configured logger every 10 seconds. It stops when the 'shutdown'
 // channel is closed.
@@ -303,6 +692,9 @@ func (c *Crawler) logMetricsPeriodically() {
 		for {
 			select {
 			case <-ticker.C: // On each tick, retrieve and log current metrics.
+				c.reportQueueDepths()
+				c.saveCheckpoint()
+
 				stats := c.Stats.GetStats()
 				pagesPerSecond := float64(0)
 				if uptime := stats["uptime_seconds"].(float64); uptime > 0 {
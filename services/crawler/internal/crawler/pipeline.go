@@ -0,0 +1,78 @@
+package crawler
+
+import (
+	// Stdlib
+	"strings"
+
+	// Internal modules
+	"github.com/sneakyhydra/sneakdex/crawler/internal/pipeline"
+)
+
+// pipelineProcessorOrder is the fixed precedence Processors are registered
+// in, regardless of the order they appear in Cfg.PipelineProcessors: sitemap
+// and feed both have to run ahead of the generic text/html fallbacks, and
+// sitemap ahead of feed since both can match a bare "application/xml"
+// Content-Type (see pipeline.SitemapProcessor).
+var pipelineProcessorOrder = []string{"sitemap", "feed", "pdf", "text", "html"}
+
+// parseTopicOverrides parses Cfg.PipelineTopicOverrides ("name=topic,...")
+// into a lookup by processor name, silently skipping malformed entries -
+// they're already rejected at startup by config.validatePipeline.
+func parseTopicOverrides(s string) map[string]string {
+	overrides := make(map[string]string)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		overrides[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return overrides
+}
+
+// containsCSV reports whether name appears as one of csv's comma-separated
+// entries.
+func containsCSV(csv, name string) bool {
+	for _, entry := range strings.Split(csv, ",") {
+		if strings.TrimSpace(entry) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// buildPipeline assembles the content-type Pipeline used by setRequestHandler
+// and setResponseHandler from Cfg.PipelineProcessors/PipelineTopicOverrides.
+// ExtensionFilter is always registered - none of the Processors above would
+// ever accept an image/font/script/archive response anyway, so skipping the
+// request entirely is strictly cheaper. Whether it also skips ".pdf" depends
+// on whether the "pdf" Processor is actually enabled below.
+func (c *Crawler) buildPipeline() *pipeline.Pipeline {
+	p := pipeline.New()
+	p.AddFilter(pipeline.NewExtensionFilter(containsCSV(c.Cfg.PipelineProcessors, "pdf")))
+
+	overrides := parseTopicOverrides(c.Cfg.PipelineTopicOverrides)
+	for _, name := range pipelineProcessorOrder {
+		if !containsCSV(c.Cfg.PipelineProcessors, name) {
+			continue
+		}
+		switch name {
+		case "sitemap":
+			p.AddProcessor(pipeline.SitemapProcessor{})
+		case "feed":
+			p.AddProcessor(pipeline.FeedProcessor{})
+		case "pdf":
+			p.AddProcessor(pipeline.PDFProcessor{Topic: overrides["pdf"]})
+		case "text":
+			p.AddProcessor(pipeline.PlainTextProcessor{Topic: overrides["text"]})
+		case "html":
+			p.AddProcessor(pipeline.HTMLProcessor{Topic: overrides["html"]})
+		}
+	}
+
+	return p
+}
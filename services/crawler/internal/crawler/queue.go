@@ -2,6 +2,7 @@ package crawler
 
 import (
 	// Stdlib
+	"sync/atomic"
 	"time"
 
 	// Third-party
@@ -11,15 +12,21 @@ import (
 )
 
 // feedCollyFromRedisQueue continuously feeds URLs from the Redis pending queue to the Colly collector.
-func (c *Crawler) feedCollyFromRedisQueue(collector *colly.Collector, doneChan chan struct{}) {
+func (c *Crawler) feedCollyFromRedisQueue(collector *colly.Collector) {
 	defer c.Wg.Done()
-	defer close(doneChan)
 
 	c.Log.Info("Starting Redis queue feeder goroutine")
 
 	emptyQueueChecks := 0
 	const maxEmptyChecks = 5
 
+	// consecutiveRedisErrs backs an exponential backoff so a transient
+	// outage (e.g. a Sentinel failover electing a new master) doesn't turn
+	// into a hot loop hammering Redis every tick; it resets to 0 on the
+	// next successful pop.
+	consecutiveRedisErrs := 0
+	const maxRedisBackoff = 5 * time.Second
+
 	ticker := time.NewTicker(5 * time.Millisecond)
 	defer ticker.Stop()
 
@@ -30,20 +37,25 @@ func (c *Crawler) feedCollyFromRedisQueue(collector *colly.Collector, doneChan c
 			collector.Wait()
 			return
 		case <-ticker.C:
+			atomic.StoreInt64(&c.FeederLastTick, time.Now().UnixNano())
+
 			// Check if page processing limit is reached
 			if c.Stats.GetPagesProcessed() >= c.Cfg.MaxPages {
 				c.Log.Info("Max page limit reached, stopping Redis queue feeder")
 				return
 			}
 
-			// Check concurrency limits before processing
-			if c.Stats.GetInflightPages() >= int64(c.Cfg.MaxConcurrency) {
+			// Check concurrency limits before processing. Read live so a
+			// MaxConcurrency change from config.Manager takes effect
+			// without a restart.
+			if c.Stats.GetInflightPages() >= int64(c.CfgManager.Current().MaxConcurrency) {
 				time.Sleep(20 * time.Millisecond) // Brief pause if at capacity
 				continue
 			}
 
-			item, err := c.RemoveFromPending()
+			item, err := c.RemoveFromPending(c.Ctx)
 			if err == redis.Nil {
+				consecutiveRedisErrs = 0
 				emptyQueueChecks++
 				c.Log.WithField("empty_checks", emptyQueueChecks).Debug("No URLs in Redis pending queue")
 
@@ -57,13 +69,23 @@ func (c *Crawler) feedCollyFromRedisQueue(collector *colly.Collector, doneChan c
 				}
 				continue
 			} else if err != nil {
-				c.Log.WithError(err).Error("Redis error while popping URL from pending queue")
+				consecutiveRedisErrs++
+				backoff := time.Duration(1<<uint(consecutiveRedisErrs)) * 50 * time.Millisecond
+				if backoff > maxRedisBackoff {
+					backoff = maxRedisBackoff
+				}
+				c.Log.WithError(err).WithFields(logrus.Fields{
+					"consecutive_errors": consecutiveRedisErrs,
+					"backoff":            backoff,
+				}).Error("Redis error while popping URL from pending queue, backing off")
+				time.Sleep(backoff)
 				continue
 			}
 
+			consecutiveRedisErrs = 0
 			emptyQueueChecks = 0 // Reset counter on successful fetch
 			c.Log.WithField("url", item.URL).Debug("Dispatching URL from Redis queue to Colly")
-			if item.Depth > c.Cfg.CrawlDepth {
+			if item.Depth > c.CfgManager.Current().CrawlDepth {
 				c.Log.WithFields(logrus.Fields{
 					"url":   item.URL,
 					"depth": item.Depth,
@@ -71,17 +93,31 @@ func (c *Crawler) feedCollyFromRedisQueue(collector *colly.Collector, doneChan c
 				continue
 			}
 
+			// Fail fast if the target host's circuit breaker is open rather
+			// than dispatching a request almost certain to fail; put the URL
+			// back on the queue so it's retried once the breaker recovers.
+			if host := hostOf(item.URL); !c.Breaker.Allow(host) {
+				c.Log.WithFields(logrus.Fields{"url": item.URL, "host": host}).Debug("Circuit breaker open for host, re-queuing URL")
+				c.AddToPending(c.Ctx, *item)
+				continue
+			}
+
 			// Visit URL using Colly (non-blocking due to Colly's internal concurrency)
 			ctx := colly.NewContext()
 			ctx.Put("depth", item.Depth)
 
+			// Track as in-flight until MarkVisited/AddToRequeued resolves it,
+			// so Shutdown can return it to the pending queue if the process
+			// dies before that happens.
+			c.InFlightItems.Store(item.URL, *item)
+
 			if err := collector.Request("GET", item.URL, nil, ctx, nil); err != nil {
 				c.Log.WithFields(logrus.Fields{
 					"url":   item.URL,
 					"error": err,
 				}).Warn("Colly failed to initiate visit, marking URL as visited to avoid requeue")
 
-				c.MarkVisited(item.URL)
+				c.MarkVisited(c.Ctx, item.URL)
 				c.Stats.IncrementPagesFailed()
 			}
 		}
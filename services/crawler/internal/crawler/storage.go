@@ -6,10 +6,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"strings"
 	"time"
 
 	// Third-party
 	"github.com/redis/go-redis/v9"
+
+	// Internal modules
+	"github.com/sneakyhydra/sneakdex/crawler/internal/config"
 )
 
 type QueueItem struct {
@@ -17,23 +21,49 @@ type QueueItem struct {
 	Depth int    `json:"depth"`
 }
 
-// getQueueKey returns the Redis key for a specific depth level
+// pendingURLsSetKey returns the dedup SET that AddToPending/RemoveFromPending
+// maintain alongside the depth queues below. It shares the
+// "{<run-id>:pending_urls}" hash tag with getQueueKey so that, on Redis
+// Cluster, the SAdd/RPush and LPop/SRem pairs in AddToPending/RemoveFromPending
+// always land on the same slot. Every key this file builds is namespaced
+// under c.RunID so that concurrent or successive runs never collide and a
+// resumed run (RUN_ID set to a previous run's ID) picks back up exactly
+// where it left off.
+func (c *Crawler) pendingURLsSetKey() string {
+	return fmt.Sprintf("crawler:{%s:pending_urls}:set", c.RunID)
+}
+
+// getQueueKey returns the Redis key for a specific depth level. It carries
+// the same "{<run-id>:pending_urls}" hash tag as pendingURLsSetKey (see
+// above) for Cluster slot-safety.
 func (c *Crawler) getQueueKey(depth int) string {
-	return fmt.Sprintf("crawler:pending_urls:depth_%d", depth)
+	return fmt.Sprintf("crawler:{%s:pending_urls}:depth_%d", c.RunID, depth)
+}
+
+// visitedKey returns the per-URL visited marker key for the current run.
+func (c *Crawler) visitedKey(url string) string {
+	return fmt.Sprintf("crawler:{%s}:visited:%s", c.RunID, url)
 }
 
-// AddToPending adds an item to the appropriate depth-based queue
-func (c *Crawler) AddToPending(item QueueItem) {
+// requeuedURLsKey returns the requeued-URLs SET key for the current run.
+func (c *Crawler) requeuedURLsKey() string {
+	return fmt.Sprintf("crawler:{%s}:requeued_urls", c.RunID)
+}
+
+// AddToPending adds an item to the appropriate depth-based queue. ctx should
+// be derived from the crawler's top-level context (c.Ctx) so that shutdown
+// aborts any in-flight Redis call instead of leaking it past CShutdown.
+func (c *Crawler) AddToPending(ctx context.Context, item QueueItem) {
 	// Check if already in pending locally
-	if _, exists := c.Pending.Load(item.URL); exists {
+	if c.PendingCache.Contains(item.URL) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), c.Cfg.RedisTimeout)
+	addCtx, cancel := context.WithTimeout(ctx, c.Cfg.RedisTimeout)
 	defer cancel()
 
 	// Add to Redis SET to deduplicate
-	added, err := c.RedisClient.SAdd(ctx, "crawler:pending_urls_set", item.URL).Result()
+	added, err := c.RedisClient.SAdd(addCtx, c.pendingURLsSetKey(), item.URL).Result()
 	if err != nil {
 		c.Log.WithField("url", item.URL).WithError(err).Error("Failed to add to pending_urls_set")
 		c.Stats.IncrementRedisErrored()
@@ -41,7 +71,7 @@ func (c *Crawler) AddToPending(item QueueItem) {
 	}
 
 	// Mark as pending locally
-	c.Pending.Store(item.URL, struct{}{})
+	c.PendingCache.Add(item.URL)
 
 	// Only push to queue if it wasn't already in the set
 	if added == 1 {
@@ -52,36 +82,43 @@ func (c *Crawler) AddToPending(item QueueItem) {
 			return
 		}
 
-		ctx2, cancel2 := context.WithTimeout(context.Background(), c.Cfg.RedisTimeout)
+		pushCtx, cancel2 := context.WithTimeout(ctx, c.Cfg.RedisTimeout)
 		defer cancel2()
 
 		// Push to depth-specific queue
 		queueKey := c.getQueueKey(item.Depth)
-		if err := c.RedisClient.RPush(ctx2, queueKey, data).Err(); err != nil {
+		if err := c.RedisClient.RPush(pushCtx, queueKey, data).Err(); err != nil {
 			c.Log.WithField("url", item.URL).WithField("depth", item.Depth).WithError(err).Error("Failed to enqueue to depth-specific queue")
 			c.Stats.IncrementRedisErrored()
 			// Optionally clean up set
-			ctx3, cancel3 := context.WithTimeout(context.Background(), c.Cfg.RedisTimeout)
+			cleanupCtx, cancel3 := context.WithTimeout(ctx, c.Cfg.RedisTimeout)
 			defer cancel3()
-			_ = c.RedisClient.SRem(ctx3, "crawler:pending_urls_set", item.URL).Err()
-			c.Pending.Delete(item.URL)
+			_ = c.RedisClient.SRem(cleanupCtx, c.pendingURLsSetKey(), item.URL).Err()
+			c.PendingCache.Remove(item.URL)
 			return
 		}
 	}
 }
 
-// RemoveFromPending removes an item from the priority queue, starting with the lowest depth
-func (c *Crawler) RemoveFromPending() (*QueueItem, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), c.Cfg.RedisTimeout)
+// RemoveFromPending removes an item from the priority queue, starting with
+// the lowest depth. ctx should be derived from the crawler's top-level
+// context (c.Ctx); when it's done, the dequeue loop returns immediately
+// instead of blocking on a stalled Redis call.
+func (c *Crawler) RemoveFromPending(ctx context.Context) (*QueueItem, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	popCtx, cancel := context.WithTimeout(ctx, c.Cfg.RedisTimeout)
 	defer cancel()
 
 	// Try each depth level starting from 0
-	maxDepth := c.Cfg.CrawlDepth
+	maxDepth := c.CfgManager.Current().CrawlDepth
 
 	for depth := 0; depth <= maxDepth; depth++ {
 		queueKey := c.getQueueKey(depth)
 
-		data, err := c.RedisClient.LPop(ctx, queueKey).Result()
+		data, err := c.RedisClient.LPop(popCtx, queueKey).Result()
 		if err == redis.Nil {
 			// No items at this depth, try next depth
 			continue
@@ -100,15 +137,15 @@ func (c *Crawler) RemoveFromPending() (*QueueItem, error) {
 		}
 
 		// Remove from set
-		ctx2, cancel2 := context.WithTimeout(context.Background(), c.Cfg.RedisTimeout)
+		remCtx, cancel2 := context.WithTimeout(ctx, c.Cfg.RedisTimeout)
 		defer cancel2()
-		if err := c.RedisClient.SRem(ctx2, "crawler:pending_urls_set", item.URL).Err(); err != nil {
+		if err := c.RedisClient.SRem(remCtx, c.pendingURLsSetKey(), item.URL).Err(); err != nil {
 			c.Log.WithField("url", item.URL).WithError(err).Error("Failed to remove from pending_urls_set")
 			c.Stats.IncrementRedisErrored()
 		}
 
 		// Remove from local pending
-		c.Pending.Delete(item.URL)
+		c.PendingCache.Remove(item.URL)
 
 		return &item, nil
 	}
@@ -123,7 +160,7 @@ func (c *Crawler) GetQueueStats() map[int]int64 {
 	defer cancel()
 
 	stats := make(map[int]int64)
-	maxDepth := c.Cfg.CrawlDepth // Should match the maxDepth in RemoveFromPending
+	maxDepth := c.CfgManager.Current().CrawlDepth // Should match the maxDepth in RemoveFromPending
 
 	for depth := 0; depth <= maxDepth; depth++ {
 		queueKey := c.getQueueKey(depth)
@@ -145,7 +182,7 @@ func (c *Crawler) CleanupEmptyQueues() {
 	ctx, cancel := context.WithTimeout(context.Background(), c.Cfg.RedisTimeout)
 	defer cancel()
 
-	maxDepth := c.Cfg.CrawlDepth // Should match the maxDepth in RemoveFromPending
+	maxDepth := c.CfgManager.Current().CrawlDepth // Should match the maxDepth in RemoveFromPending
 
 	for depth := 0; depth <= maxDepth; depth++ {
 		queueKey := c.getQueueKey(depth)
@@ -160,64 +197,99 @@ func (c *Crawler) CleanupEmptyQueues() {
 	}
 }
 
-// preloadLocalCaches - updated to handle multiple depth queues
-func (c *Crawler) preloadLocalCaches() {
-	c.Log.Info("Preloading local caches from Redis to minimize future Redis calls...")
-
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	// Load visited URLs (sample to avoid memory issues)
-	visitedURLs, err := c.RedisClient.SRandMemberN(ctx, "crawler:visited_urls", 10000).Result()
-	if err != nil && err != redis.Nil {
-		c.Log.WithError(err).Warn("Failed to preload visited URLs")
-	} else {
-		for _, url := range visitedURLs {
-			c.Seen.Store(url, struct{}{})
+// scanAndDelete deletes every key matching pattern on a single Redis node,
+// paging through SCAN rather than KEYS so it doesn't block the node on a
+// large keyspace.
+func scanAndDelete(ctx context.Context, client redis.Cmdable, pattern string) error {
+	var cursor uint64
+	for {
+		keys, next, err := client.Scan(ctx, cursor, pattern, 100).Result()
+		if err != nil {
+			return err
 		}
-		c.Log.Infof("Preloaded %d visited URLs into local cache", len(visitedURLs))
-	}
-
-	// Load pending URLs (sample to avoid memory issues)
-	pendingURLs, err := c.RedisClient.SRandMemberN(ctx, "crawler:pending_urls_set", 5000).Result()
-	if err != nil && err != redis.Nil {
-		c.Log.WithError(err).Warn("Failed to preload pending URLs")
-	} else {
-		for _, url := range pendingURLs {
-			c.Seen.Store(url, struct{}{})
-			c.Pending.Store(url, struct{}{})
+		if len(keys) > 0 {
+			if err := client.Del(ctx, keys...).Err(); err != nil {
+				return err
+			}
+		}
+		cursor = next
+		if cursor == 0 {
+			return nil
 		}
-		c.Log.Infof("Preloaded %d pending URLs into local cache", len(pendingURLs))
 	}
+}
 
-	// Load requeued URLs (all of them since there should be fewer)
-	requeuedURLs, err := c.RedisClient.SMembers(ctx, "crawler:requeued_urls").Result()
-	if err != nil && err != redis.Nil {
-		c.Log.WithError(err).Warn("Failed to preload requeued URLs")
-	} else {
-		for _, url := range requeuedURLs {
-			c.Seen.Store(url, struct{}{})
-			c.Requeued.Store(url, struct{}{})
-		}
-		c.Log.Infof("Preloaded %d requeued URLs into local cache", len(requeuedURLs))
+// purgeRunState deletes every Redis key namespaced under c.RunID - pending
+// queues, visited markers, requeued set, checkpoint, retry counters - used
+// when Cfg.Resume is false and RunID was explicitly set, so the run starts
+// genuinely fresh instead of silently resuming whatever state that ID
+// already holds. On a redis.ClusterClient this runs per-shard, matching
+// checkRedisHealth's ForEachShard pattern in the monitor package.
+func (c *Crawler) purgeRunState(ctx context.Context) error {
+	// The glob is anchored right after RunID with a character class rather
+	// than a bare "}", because this run's keys use two different hash-tag
+	// forms: "{<RunID>}" (visitedKey/requeuedURLsKey, and the checkpoint and
+	// retry packages) and "{<RunID>:pending_urls}" (pendingURLsSetKey/
+	// getQueueKey). An unanchored "crawler:{%s*" would also match any other
+	// RunID that has this one as a string prefix (e.g. purging "1" would
+	// delete "10"'s state too); closing with "[}:]" anchors to exactly this
+	// RunID while still matching both hash-tag forms.
+	pattern := fmt.Sprintf("crawler:{%s[}:]*", c.RunID)
+
+	if cluster, ok := c.RedisClient.(*redis.ClusterClient); ok {
+		return cluster.ForEachShard(ctx, func(ctx context.Context, shard *redis.Client) error {
+			return scanAndDelete(ctx, shard, pattern)
+		})
 	}
 
-	c.Log.Info("Local cache preloading completed")
+	return scanAndDelete(ctx, c.RedisClient, pattern)
+}
+
+// newRedisUniversalClient builds the redis.UniversalClient implementation
+// selected by cfg.RedisMode. cfg.Validate has already rejected any other
+// value and confirmed the mode-specific addresses are non-empty.
+func newRedisUniversalClient(cfg *config.Config) (redis.UniversalClient, string) {
+	switch cfg.RedisMode {
+	case "sentinel":
+		addrs := strings.Split(cfg.RedisSentinelAddrs, ",")
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       cfg.RedisSentinelMasterName,
+			SentinelAddrs:    addrs,
+			SentinelPassword: cfg.RedisSentinelPassword,
+			Password:         cfg.RedisPassword,
+			DB:               cfg.RedisDB,
+			DialTimeout:      cfg.RedisTimeout,
+			ReadTimeout:      cfg.RedisTimeout,
+			WriteTimeout:     cfg.RedisTimeout,
+			MaxRetries:       cfg.RedisRetryMax,
+		}), strings.Join(addrs, ",")
+	case "cluster":
+		addrs := strings.Split(cfg.RedisClusterAddrs, ",")
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:        addrs,
+			Password:     cfg.RedisPassword,
+			DialTimeout:  cfg.RedisTimeout,
+			ReadTimeout:  cfg.RedisTimeout,
+			WriteTimeout: cfg.RedisTimeout,
+			MaxRetries:   cfg.RedisRetryMax,
+		}), strings.Join(addrs, ",")
+	default: // "standalone"
+		addr := fmt.Sprintf("%s:%d", cfg.RedisHost, cfg.RedisPort)
+		return redis.NewClient(&redis.Options{
+			Addr:         addr,
+			Password:     cfg.RedisPassword,
+			DB:           cfg.RedisDB,
+			DialTimeout:  cfg.RedisTimeout,
+			ReadTimeout:  cfg.RedisTimeout,
+			WriteTimeout: cfg.RedisTimeout,
+			MaxRetries:   cfg.RedisRetryMax,
+		}), addr
+	}
 }
 
 func (c *Crawler) initializeRedis() error {
-	redisAddr := fmt.Sprintf("%s:%d", c.Cfg.RedisHost, c.Cfg.RedisPort)
-
-	// Initialize Redis client
-	c.RedisClient = redis.NewClient(&redis.Options{
-		Addr:         redisAddr,
-		Password:     c.Cfg.RedisPassword,
-		DB:           c.Cfg.RedisDB,
-		DialTimeout:  c.Cfg.RedisTimeout,
-		ReadTimeout:  c.Cfg.RedisTimeout,
-		WriteTimeout: c.Cfg.RedisTimeout,
-		MaxRetries:   c.Cfg.RedisRetryMax,
-	})
+	client, addr := newRedisUniversalClient(c.Cfg)
+	c.RedisClient = client
 
 	// Attempt to connect with retries
 	for attempt := 1; attempt <= c.Cfg.RedisRetryMax; attempt++ {
@@ -226,7 +298,7 @@ func (c *Crawler) initializeRedis() error {
 		err := c.RedisClient.Ping(ctx).Err()
 
 		if err == nil {
-			c.Log.Infof("Redis connection established at %s", redisAddr)
+			c.Log.Infof("Redis connection established (mode=%s addr=%s)", c.Cfg.RedisMode, addr)
 			return nil
 		}
 
@@ -238,19 +310,24 @@ func (c *Crawler) initializeRedis() error {
 		}
 	}
 
-	return fmt.Errorf("failed to connect to Redis after %d attempts (addr: %s)", c.Cfg.RedisRetryMax, redisAddr)
+	return fmt.Errorf("failed to connect to Redis after %d attempts (mode=%s addr=%s)", c.Cfg.RedisRetryMax, c.Cfg.RedisMode, addr)
 }
 
-func (c *Crawler) isURLSeen(url string) (bool, error) {
-	// Check local cache first
-	if _, exists := c.Seen.Load(url); exists {
+func (c *Crawler) isURLSeen(ctx context.Context, url string) (bool, error) {
+	// Check local LRU cache first
+	if c.SeenCache.Contains(url) {
 		return true, nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), c.Cfg.RedisTimeout)
+	start := time.Now()
+	defer func() {
+		c.Stats.ObserveOperation("redis_dedup", hostOf(url), time.Since(start).Seconds())
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, c.Cfg.RedisTimeout)
 	defer cancel()
 
-	key := fmt.Sprintf("crawler:visited:%s", url)
+	key := c.visitedKey(url)
 
 	exists, err := c.RedisClient.Exists(ctx, key).Result()
 	if err != nil {
@@ -261,6 +338,7 @@ func (c *Crawler) isURLSeen(url string) (bool, error) {
 
 	if exists > 0 {
 		c.Stats.IncrementRedisSuccessful()
+		c.SeenCache.Add(url)
 		return true, nil
 	}
 
@@ -269,8 +347,8 @@ func (c *Crawler) isURLSeen(url string) (bool, error) {
 }
 
 func (c *Crawler) isURLRequeued(url string) (bool, error) {
-	// Check in-memory local cache first
-	if _, exists := c.Requeued.Load(url); exists {
+	// Check local LRU cache first
+	if c.RequeuedCache.Contains(url) {
 		c.Log.WithField("url", url).Trace("URL found in local cache")
 		return true, nil
 	}
@@ -287,11 +365,11 @@ func (c *Crawler) isURLRequeued(url string) (bool, error) {
 	return false, nil
 }
 
-func (c *Crawler) MarkVisited(url string) {
-	ctx, cancel := context.WithTimeout(context.Background(), c.Cfg.RedisTimeout)
+func (c *Crawler) MarkVisited(ctx context.Context, url string) {
+	ctx, cancel := context.WithTimeout(ctx, c.Cfg.RedisTimeout)
 	defer cancel()
 
-	key := fmt.Sprintf("crawler:visited:%s", url)
+	key := c.visitedKey(url)
 	ttl := 24 * time.Hour // or make configurable
 
 	if err := c.RedisClient.Set(ctx, key, "1", ttl).Err(); err != nil {
@@ -300,37 +378,49 @@ func (c *Crawler) MarkVisited(url string) {
 		return
 	}
 
-	c.Seen.Store(url, struct{}{})
+	c.SeenCache.Add(url)
+	c.InFlightItems.Delete(url)
+	c.Stats.IncrementVisitedMarked()
 }
 
-func (c *Crawler) AddToRequeued(url string) {
-	ctx, cancel := context.WithTimeout(context.Background(), c.Cfg.RedisTimeout)
+func (c *Crawler) AddToRequeued(ctx context.Context, url string) {
+	ctx, cancel := context.WithTimeout(ctx, c.Cfg.RedisTimeout)
 	defer cancel()
 
-	c.Requeued.Store(url, struct{}{})
-	if err := c.RedisClient.SAdd(ctx, "crawler:requeued_urls", url).Err(); err != nil {
+	c.RequeuedCache.Add(url)
+	if err := c.RedisClient.SAdd(ctx, c.requeuedURLsKey(), url).Err(); err != nil {
 		c.Log.WithField("url", url).WithError(err).Error("Failed to add to requeued_urls")
 		c.Stats.IncrementRedisErrored()
 	}
+	c.InFlightItems.Delete(url)
+}
+
+// GetRequeuedCount returns the size of the requeued-URLs set for the current
+// run, used by reportQueueDepths to populate crawler_queue_depth{queue="requeued"}.
+func (c *Crawler) GetRequeuedCount() (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.Cfg.RedisTimeout)
+	defer cancel()
+
+	return c.RedisClient.SCard(ctx, c.requeuedURLsKey()).Result()
 }
 
 func (c *Crawler) RemoveFromRequeued(url string) {
 	ctx, cancel := context.WithTimeout(context.Background(), c.Cfg.RedisTimeout)
 	defer cancel()
 
-	if err := c.RedisClient.SRem(ctx, "crawler:requeued_urls", url).Err(); err != nil {
+	if err := c.RedisClient.SRem(ctx, c.requeuedURLsKey(), url).Err(); err != nil {
 		c.Log.WithField("url", url).WithError(err).Error("Failed to remove from requeued_urls")
 		c.Stats.IncrementRedisErrored()
 	}
 
-	c.Requeued.Delete(url)
+	c.RequeuedCache.Remove(url)
 }
 
 func (c *Crawler) IsRequeued(url string) (bool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), c.Cfg.RedisTimeout)
 	defer cancel()
 
-	exists, err := c.RedisClient.SIsMember(ctx, "crawler:requeued_urls", url).Result()
+	exists, err := c.RedisClient.SIsMember(ctx, c.requeuedURLsKey(), url).Result()
 	if err != nil {
 		c.Log.WithField("url", url).WithError(err).Error("Failed to check requeued_urls")
 		c.Stats.IncrementRedisErrored()
@@ -0,0 +1,232 @@
+package crawler
+
+import (
+	// Stdlib
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	// Third-party
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	// Internal modules
+	"github.com/sneakyhydra/sneakdex/crawler/internal/config"
+)
+
+// TestInitializeRedis_Standalone exercises newRedisUniversalClient/
+// initializeRedis's default "standalone" dispatch against a real (in-memory)
+// Redis server, so the happy path - connect, ping, done - is covered without
+// a live Redis dependency.
+func TestInitializeRedis_Standalone(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	host, portStr, err := net.SplitHostPort(mr.Addr())
+	if err != nil {
+		t.Fatalf("splitting miniredis addr %q: %v", mr.Addr(), err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		t.Fatalf("parsing miniredis port %q: %v", portStr, err)
+	}
+
+	c := &Crawler{
+		Log: logrus.New(),
+		Cfg: &config.Config{
+			RedisMode:     "standalone",
+			RedisHost:     host,
+			RedisPort:     port,
+			RedisTimeout:  time.Second,
+			RedisRetryMax: 1,
+		},
+	}
+
+	if err := c.initializeRedis(); err != nil {
+		t.Fatalf("initializeRedis() standalone: %v", err)
+	}
+	if _, ok := c.RedisClient.(*redis.Client); !ok {
+		t.Fatalf("expected *redis.Client for standalone mode, got %T", c.RedisClient)
+	}
+}
+
+// TestInitializeRedis_Sentinel exercises the "sentinel" dispatch against a
+// stubbed Sentinel server that answers just enough of the protocol - PING
+// and SENTINEL GET-MASTER-ADDR-BY-NAME - for go-redis's FailoverClient to
+// resolve and ping the real miniredis master behind it.
+func TestInitializeRedis_Sentinel(t *testing.T) {
+	mr := miniredis.RunT(t)
+	masterHost, masterPort, err := net.SplitHostPort(mr.Addr())
+	if err != nil {
+		t.Fatalf("splitting miniredis addr %q: %v", mr.Addr(), err)
+	}
+
+	sentinel := newStubSentinel(t, masterHost, masterPort)
+	defer sentinel.Close()
+
+	c := &Crawler{
+		Log: logrus.New(),
+		Cfg: &config.Config{
+			RedisMode:               "sentinel",
+			RedisSentinelAddrs:      sentinel.addr,
+			RedisSentinelMasterName: "mymaster",
+			RedisTimeout:            time.Second,
+			RedisRetryMax:           1,
+		},
+	}
+
+	if err := c.initializeRedis(); err != nil {
+		t.Fatalf("initializeRedis() sentinel: %v", err)
+	}
+	if _, ok := c.RedisClient.(*redis.Client); !ok {
+		t.Fatalf("expected *redis.Client for sentinel mode, got %T", c.RedisClient)
+	}
+}
+
+// stubSentinel is a minimal RESP server standing in for a real Redis
+// Sentinel: it answers PING, SENTINEL GET-MASTER-ADDR-BY-NAME with a fixed
+// master address, and SUBSCRIBE (for the failover client's +switch-master
+// watch) with a bare subscribe confirmation. HELLO gets a RESP error (see
+// handle), and anything else gets a generic +OK so an unexpected command
+// doesn't hang the connection.
+type stubSentinel struct {
+	ln         net.Listener
+	addr       string
+	masterHost string
+	masterPort string
+}
+
+func newStubSentinel(t *testing.T, masterHost, masterPort string) *stubSentinel {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("starting stub sentinel listener: %v", err)
+	}
+
+	s := &stubSentinel{ln: ln, addr: ln.Addr().String(), masterHost: masterHost, masterPort: masterPort}
+	go s.serve()
+	return s
+}
+
+func (s *stubSentinel) Close() error {
+	return s.ln.Close()
+}
+
+func (s *stubSentinel) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *stubSentinel) handle(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	for {
+		args, err := readRESPCommand(r)
+		if err != nil {
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		switch strings.ToUpper(args[0]) {
+		case "HELLO":
+			// go-redis always tries HELLO first to negotiate RESP3. A real
+			// Sentinel predating HELLO answers with a RESP error, which
+			// go-redis treats as "falls back to RESP2" (see its isRedisError
+			// check in baseClient.initConn); replying "+OK" instead is a
+			// well-formed reply of the wrong shape, which go-redis can't
+			// parse as HELLO's map reply and fails the connection outright.
+			io.WriteString(conn, "-ERR unknown command 'HELLO'\r\n")
+		case "PING":
+			io.WriteString(conn, "+PONG\r\n")
+		case "SENTINEL":
+			if len(args) >= 2 && strings.EqualFold(args[1], "get-master-addr-by-name") {
+				writeRESPArray(conn, []string{s.masterHost, s.masterPort})
+				continue
+			}
+			writeRESPArray(conn, nil)
+		case "SUBSCRIBE":
+			if len(args) >= 2 {
+				writeSubscribeConfirmation(conn, args[1])
+			}
+		default:
+			io.WriteString(conn, "+OK\r\n")
+		}
+	}
+}
+
+// readRESPCommand reads one RESP request - the "*<n>\r\n($<len>\r\n<bytes>\r\n)*"
+// array-of-bulk-strings form every Redis client, including go-redis, sends
+// commands in.
+func readRESPCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("unexpected RESP prefix %q", line)
+	}
+
+	n, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, fmt.Errorf("parsing RESP array length %q: %w", line, err)
+	}
+
+	args := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		head, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		head = strings.TrimRight(head, "\r\n")
+		if !strings.HasPrefix(head, "$") {
+			return nil, fmt.Errorf("unexpected RESP bulk header %q", head)
+		}
+		l, err := strconv.Atoi(head[1:])
+		if err != nil {
+			return nil, fmt.Errorf("parsing RESP bulk length %q: %w", head, err)
+		}
+
+		buf := make([]byte, l+2) // payload plus trailing \r\n
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args = append(args, string(buf[:l]))
+	}
+	return args, nil
+}
+
+// writeRESPArray writes items as a RESP array of bulk strings, or a RESP
+// nil array ("*-1\r\n") when items is nil.
+func writeRESPArray(w io.Writer, items []string) {
+	if items == nil {
+		io.WriteString(w, "*-1\r\n")
+		return
+	}
+	fmt.Fprintf(w, "*%d\r\n", len(items))
+	for _, item := range items {
+		fmt.Fprintf(w, "$%d\r\n%s\r\n", len(item), item)
+	}
+}
+
+// writeSubscribeConfirmation writes a SUBSCRIBE reply for channel: a 3-element
+// array of ["subscribe", <channel>, <count>], where, unlike the bulk-string
+// elements writeRESPArray produces, the trailing count must be a RESP
+// integer - go-redis's PubSub.newMessage type-asserts it straight to int64.
+func writeSubscribeConfirmation(w io.Writer, channel string) {
+	fmt.Fprintf(w, "*3\r\n$9\r\nsubscribe\r\n$%d\r\n%s\r\n:1\r\n", len(channel), channel)
+}
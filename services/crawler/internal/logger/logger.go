@@ -43,3 +43,16 @@ func NewLogger(logLevel string) (*logrus.Logger, error) {
 	log.SetOutput(os.Stdout)
 	return log, nil
 }
+
+// SetLevel parses logLevel and applies it to log, for subscribing the
+// shared logger to a live config.Manager reload (logrus.Logger.SetLevel is
+// safe to call concurrently with in-flight logging). It leaves the current
+// level untouched and returns an error if logLevel doesn't parse.
+func SetLevel(log *logrus.Logger, logLevel string) error {
+	level, err := logrus.ParseLevel(logLevel)
+	if err != nil {
+		return fmt.Errorf("invalid log level '%s': %w", logLevel, err)
+	}
+	log.SetLevel(level)
+	return nil
+}
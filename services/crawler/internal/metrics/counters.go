@@ -20,6 +20,17 @@ func (m *Metrics) IncrementPagesSuccessful() { atomic.AddInt64(&m.PagesSuccessfu
 // Increment pages that failed to process.
 func (m *Metrics) IncrementPagesFailed() { atomic.AddInt64(&m.PagesFailed, 1) }
 
+// Increment pages that were re-queued after a retriable fetch or send failure instead
+// of being given up on.
+func (m *Metrics) IncrementPagesRequeued() { atomic.AddInt64(&m.PagesRequeued, 1) }
+
+// Increment the count of URLs marked visited in this process's lifetime,
+// backing crawler_queue_depth{queue="visited"} (see Crawler.reportQueueDepths).
+// Unlike the pending/requeued depths, Redis has no single "visited" set to
+// SCARD - MarkVisited writes one TTL'd key per URL - so this in-process
+// counter is the closest approximation and resets across restarts.
+func (m *Metrics) IncrementVisitedMarked() { atomic.AddInt64(&m.VisitedMarked, 1) }
+
 // Increment pages that were successfully sent to Kafka.
 func (m *Metrics) IncrementKafkaSuccessful() { atomic.AddInt64(&m.KafkaSuccessful, 1) }
 
@@ -29,6 +40,24 @@ func (m *Metrics) IncrementKafkaFailed() { atomic.AddInt64(&m.KafkaFailed, 1) }
 // Increment pages that errored while sending to Kafka (e.g., connection issues).
 func (m *Metrics) IncrementKafkaErrored() { atomic.AddInt64(&m.KafkaErrored, 1) }
 
+// RestoreCounters overwrites the running totals from a prior run's
+// checkpoint.Snapshot, used once by crawler.New when resuming so pages
+// already counted before a restart aren't silently dropped back to zero.
+// It's called before any goroutine starts incrementing these, so a plain
+// atomic.StoreInt64 per field is safe.
+func (m *Metrics) RestoreCounters(pagesProcessed, pagesSuccessful, pagesFailed, pagesRequeued, kafkaSuccessful, kafkaFailed, kafkaErrored, redisSuccessful, redisFailed, redisErrored int64) {
+	atomic.StoreInt64(&m.PagesProcessed, pagesProcessed)
+	atomic.StoreInt64(&m.PagesSuccessful, pagesSuccessful)
+	atomic.StoreInt64(&m.PagesFailed, pagesFailed)
+	atomic.StoreInt64(&m.PagesRequeued, pagesRequeued)
+	atomic.StoreInt64(&m.KafkaSuccessful, kafkaSuccessful)
+	atomic.StoreInt64(&m.KafkaFailed, kafkaFailed)
+	atomic.StoreInt64(&m.KafkaErrored, kafkaErrored)
+	atomic.StoreInt64(&m.RedisSuccessful, redisSuccessful)
+	atomic.StoreInt64(&m.RedisFailed, redisFailed)
+	atomic.StoreInt64(&m.RedisErrored, redisErrored)
+}
+
 // Increment successful Redis checks or operations.
 func (m *Metrics) IncrementRedisSuccessful() { atomic.AddInt64(&m.RedisSuccessful, 1) }
 
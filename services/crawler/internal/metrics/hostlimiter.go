@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	// StdLib
+	"net"
+	"strings"
+	"sync"
+)
+
+// hostLimiter caps the number of distinct "host" label values handed to
+// Prometheus to at most capacity: the first capacity distinct hosts it
+// sees each keep their own label for good, and every host first seen after
+// that is folded into otherHostLabel. Slots go to hosts in arrival order,
+// not by volume, and are never evicted or reassigned, so a high-volume host
+// first seen once the limiter is full is always reported as otherHostLabel.
+// This keeps per-host series bounded under a long tail of distinct sites,
+// or an attacker cycling through hostnames.
+type hostLimiter struct {
+	capacity int
+
+	mu    sync.Mutex
+	hosts map[string]struct{}
+}
+
+func newHostLimiter(capacity int) *hostLimiter {
+	return &hostLimiter{
+		capacity: capacity,
+		hosts:    make(map[string]struct{}, capacity),
+	}
+}
+
+// label returns the Prometheus label value to use for host: host itself if
+// it's already tracked or there's still room to track it, otherwise
+// otherHostLabel.
+func (l *hostLimiter) label(host string) string {
+	if host == "" {
+		host = "unknown"
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.hosts[host]; ok {
+		return host
+	}
+	if len(l.hosts) >= l.capacity {
+		return otherHostLabel
+	}
+
+	l.hosts[host] = struct{}{}
+	return host
+}
+
+// eTLD1 returns a best-effort eTLD+1 (registrable domain) for host, so that
+// metrics group subdomains of the same site (e.g. "a.example.com" and
+// "b.example.com") under one label instead of one series each. This is a
+// lightweight last-two-labels heuristic rather than a full public-suffix
+// list lookup: it's wrong for multi-part TLDs like "co.uk", but that only
+// costs a bit of extra cardinality, not correctness of the crawl itself.
+func eTLD1(host string) string {
+	host = strings.ToLower(host)
+	if i := strings.LastIndexByte(host, ':'); i >= 0 {
+		if _, _, err := net.SplitHostPort(host); err == nil {
+			host = host[:i]
+		}
+	}
+
+	if host == "" {
+		return "unknown"
+	}
+	if net.ParseIP(host) != nil {
+		return host
+	}
+
+	labels := strings.Split(host, ".")
+	if len(labels) <= 2 {
+		return host
+	}
+	return strings.Join(labels[len(labels)-2:], ".")
+}
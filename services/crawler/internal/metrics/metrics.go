@@ -1,31 +1,72 @@
 // Package metrics provides comprehensive performance monitoring and statistics collection
 // for the web crawler. It implements thread-safe counters for various crawler operations
-// and integrates with Prometheus for external monitoring and alerting.
+// and fans per-host/per-operation observations out to one or more pluggable Sink
+// backends (Prometheus, StatsD, ...), in addition to integrating directly with
+// Prometheus for the handful of always-on gauges/counters below.
 //
 // The metrics system tracks:
 //   - Page processing statistics (total, successful, failed)
 //   - Kafka message delivery statistics (successful, failed, errored)
 //   - Redis operation statistics (successful, failed, errored)
+//   - Per-operation latency (fetch, parse, kafka publish, redis dedup), bucketed per host
+//   - Per-host/outcome page and Kafka publish counters, plus request duration,
+//     response size and Kafka publish latency histograms (see ObservePageFetch
+//     and ObserveKafkaPublish), with the host label capped at trackedHosts
+//     distinct values (see hostLimiter) so a
+//     long tail of distinct sites can't blow up cardinality in any sink
 //   - System uptime and performance ratios
 //
 // All counters use atomic operations for thread safety and are exposed both as
-// internal statistics (via GetStats) and as Prometheus metrics for integration
-// with monitoring infrastructure.
+// internal statistics (via GetStats) and as live Prometheus metrics backed directly
+// by the same atomic values (via CounterFunc/GaugeFunc), so there is no separate
+// "sync" step that can drift from the real numbers. These always go to Prometheus
+// regardless of METRICS_SINKS, since they're inherently pull-based (computed at
+// scrape time) rather than observations a Sink can be pushed.
 //
 // Example usage:
 //
-//	metrics := NewMetrics()
+//	metrics, err := NewMetrics(cfg)
 //	metrics.IncrementPagesProcessed()
 //	stats := metrics.GetStats() // Get current statistics
-//	metrics.SyncPrometheusMetrics() // Update Prometheus gauges
+//	metrics.ObserveOperation("Fetch", "example.com", elapsed.Seconds())
+//	metrics.ObservePageFetch("example.com", 200, elapsed, len(body))
 package metrics
 
 import (
 	// StdLib
+	"fmt"
+	"hash/fnv"
+	"strings"
 	"time"
 
 	// Third-party
 	"github.com/prometheus/client_golang/prometheus"
+
+	// Internal modules
+	"github.com/sneakyhydra/sneakdex/crawler/internal/config"
+)
+
+// hostBuckets bounds the cardinality of the "host" label on per-operation
+// histograms: instead of one series per distinct host (unbounded, attacker
+// controlled), hosts are hashed into a small fixed number of buckets.
+const hostBuckets = 32
+
+// trackedHosts bounds the number of distinct eTLD+1 values ObservePageFetch
+// and ObserveKafkaPublish will track as their own "host" label before
+// folding overflow hosts into otherHostLabel. Unlike hostBuckets (which
+// trades host identity for a fixed bucket count everywhere), this keeps
+// real host names for the first trackedHosts sites seen.
+const trackedHosts = 256
+
+// otherHostLabel is the overflow label used once trackedHosts distinct hosts
+// are already being tracked.
+const otherHostLabel = "__other__"
+
+// Build-time version metadata, overridable via -ldflags
+// (e.g. -X github.com/sneakyhydra/sneakdex/crawler/internal/metrics.Version=1.2.3).
+var (
+	Version = "dev"
+	Commit  = "unknown"
 )
 
 // Metrics holds counters for crawler performance statistics.
@@ -34,6 +75,8 @@ type Metrics struct {
 	PagesProcessed  int64 // Total number of pages processed in the HTMLHandler.
 	PagesSuccessful int64 // Number of pages processed successfully.
 	PagesFailed     int64 // Number of pages that failed to process.
+	PagesRequeued   int64 // Number of pages re-queued after a retriable fetch or send failure.
+	VisitedMarked   int64 // Number of URLs marked visited in this process's lifetime.
 	KafkaSuccessful int64 // Number of pages successfully sent to Kafka.
 	KafkaFailed     int64 // Number of pages that failed to send to Kafka because of conditions (e.g., msg too large).
 	KafkaErrored    int64 // Number of pages that errored while sending to Kafka (e.g., connection issues).
@@ -43,91 +86,241 @@ type Metrics struct {
 
 	startTime time.Time // startTime records the time when the Metrics instance was created.
 
-	// Prometheus metrics
-	inflightPagesGauge prometheus.Gauge
+	// sinks is every metrics.Sink configured via METRICS_SINKS. Per-host and
+	// per-operation observations (ObserveOperation, ObservePageFetch,
+	// ObserveKafkaPublish, IncrementConfigReload) fan out to all of them via
+	// incrCounter/observeHistogram.
+	sinks []Sink
 
-	pagesProcessedGauge  prometheus.Gauge
-	pagesSuccessfulGauge prometheus.Gauge
-	pagesFailedGauge     prometheus.Gauge
+	// hosts bounds the cardinality of the "host" tag passed to sinks by
+	// ObservePageFetch/ObserveKafkaPublish.
+	hosts *hostLimiter
 
-	kafkaSuccessfulGauge prometheus.Gauge
-	kafkaFailedGauge     prometheus.Gauge
-	kafkaErroredGauge    prometheus.Gauge
-
-	redisSuccessfulGauge prometheus.Gauge
-	redisFailedGauge     prometheus.Gauge
-	redisErroredGauge    prometheus.Gauge
+	buildInfo prometheus.Gauge
+}
 
-	uptimeGauge prometheus.Gauge
+// newSink constructs the metrics.Sink backend named kind ("prometheus",
+// "statsd" or "noop"), as selected by one entry of cfg.MetricsSinks.
+// bucketsByName carries the non-default histogram bucket sets the
+// "prometheus" sink should use; it's ignored by the other kinds.
+func newSink(kind string, cfg *config.Config, bucketsByName map[string][]float64) (Sink, error) {
+	switch kind {
+	case "prometheus":
+		return newPromSink(nil, bucketsByName), nil
+	case "statsd":
+		return newStatsdSink(cfg.StatsDHost, cfg.StatsDPort, cfg.StatsDPrefix, cfg.StatsDFlushInterval)
+	case "noop":
+		return noopSink{}, nil
+	default:
+		return nil, fmt.Errorf("unknown metrics sink %q", kind)
+	}
 }
 
-// NewMetrics creates and initializes a new Metrics instance with current time and registers all Prometheus gauges.
-func NewMetrics() *Metrics {
+// NewMetrics creates and initializes a new Metrics instance with current time, builds
+// the Sink(s) named by cfg.MetricsSinks, and registers the always-on Prometheus
+// counters/gauges (backed directly by the atomic fields above) plus a build-info gauge.
+func NewMetrics(cfg *config.Config) (*Metrics, error) {
 	m := &Metrics{
 		startTime: time.Now(),
+		hosts:     newHostLimiter(trackedHosts),
+	}
+
+	// durationBuckets spans 10ms-60s, suitable for fetch/publish latency;
+	// crawler_operation_duration_seconds keeps the Prometheus client's
+	// broader DefBuckets default since it covers a wider mix of operations.
+	durationBuckets := []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60}
+	bucketsByName := map[string][]float64{
+		"crawler_request_duration_seconds": durationBuckets,
+		"crawler_kafka_publish_seconds":    durationBuckets,
+		"crawler_response_bytes":           prometheus.ExponentialBuckets(256, 4, 10), // 256B .. ~67MB
+	}
 
-		inflightPagesGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+	for _, kind := range strings.Split(cfg.MetricsSinks, ",") {
+		kind = strings.TrimSpace(kind)
+		if kind == "" {
+			continue
+		}
+		sink, err := newSink(kind, cfg, bucketsByName)
+		if err != nil {
+			return nil, fmt.Errorf("initializing metrics sink: %w", err)
+		}
+		m.sinks = append(m.sinks, sink)
+	}
+
+	prometheus.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
 			Name: "crawler_inflight_pages",
 			Help: "Number of pages currently being processed",
-		}),
-		pagesProcessedGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+		}, func() float64 { return float64(m.GetInflightPages()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
 			Name: "crawler_pages_processed_total",
 			Help: "Total number of pages processed",
-		}),
-		pagesSuccessfulGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+		}, func() float64 { return float64(m.GetPagesProcessed()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
 			Name: "crawler_pages_successful_total",
 			Help: "Total number of pages successfully processed",
-		}),
-		pagesFailedGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+		}, func() float64 { return float64(m.GetPagesSuccessful()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
 			Name: "crawler_pages_failed_total",
 			Help: "Total number of pages failed",
-		}),
-		kafkaSuccessfulGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+		}, func() float64 { return float64(m.GetPagesFailed()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "crawler_pages_requeued_total",
+			Help: "Total number of pages re-queued after a retriable fetch or send failure",
+		}, func() float64 { return float64(m.GetPagesRequeued()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
 			Name: "crawler_kafka_successful_total",
 			Help: "Successful Kafka messages sent",
-		}),
-		kafkaFailedGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+		}, func() float64 { return float64(m.GetKafkaSuccessful()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
 			Name: "crawler_kafka_failed_total",
 			Help: "Failed Kafka messages",
-		}),
-		kafkaErroredGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+		}, func() float64 { return float64(m.GetKafkaFailed()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
 			Name: "crawler_kafka_errored_total",
 			Help: "Errored Kafka messages",
-		}),
-		redisSuccessfulGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+		}, func() float64 { return float64(m.GetKafkaErrored()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
 			Name: "crawler_redis_successful_total",
 			Help: "Successful Redis writes",
-		}),
-		redisFailedGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+		}, func() float64 { return float64(m.GetRedisSuccessful()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
 			Name: "crawler_redis_failed_total",
 			Help: "Failed Redis writes",
-		}),
-		redisErroredGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+		}, func() float64 { return float64(m.GetRedisFailed()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
 			Name: "crawler_redis_errored_total",
 			Help: "Errored Redis writes",
-		}),
-		uptimeGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+		}, func() float64 { return float64(m.GetRedisErrored()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
 			Name: "crawler_uptime_seconds",
 			Help: "Crawler uptime in seconds",
-		}),
+		}, m.Uptime),
+	)
+
+	m.buildInfo = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:        "crawler_build_info",
+		Help:        "Build information for the running crawler binary",
+		ConstLabels: prometheus.Labels{"version": Version, "commit": Commit},
+	})
+	prometheus.MustRegister(m.buildInfo)
+	m.buildInfo.Set(1)
+
+	return m, nil
+}
+
+// incrCounter fans delta out to every configured sink's IncrCounter.
+func (m *Metrics) incrCounter(name string, tags map[string]string, delta int64) {
+	for _, s := range m.sinks {
+		s.IncrCounter(name, tags, delta)
 	}
+}
 
-	// Register all metrics
-	prometheus.MustRegister(
-		m.inflightPagesGauge,
-		m.pagesProcessedGauge,
-		m.pagesSuccessfulGauge,
-		m.pagesFailedGauge,
-		m.kafkaSuccessfulGauge,
-		m.kafkaFailedGauge,
-		m.kafkaErroredGauge,
-		m.redisSuccessfulGauge,
-		m.redisFailedGauge,
-		m.redisErroredGauge,
-		m.uptimeGauge,
-	)
+// observeHistogram fans value out to every configured sink's ObserveHistogram.
+func (m *Metrics) observeHistogram(name string, tags map[string]string, value float64) {
+	for _, s := range m.sinks {
+		s.ObserveHistogram(name, tags, value)
+	}
+}
+
+// setGauge fans value out to every configured sink's SetGauge.
+func (m *Metrics) setGauge(name string, tags map[string]string, value float64) {
+	for _, s := range m.sinks {
+		s.SetGauge(name, tags, value)
+	}
+}
+
+// ObserveOperation records how long an operation (e.g. "Fetch", "Parse", "Store",
+// "kafka_publish", "redis_dedup") took for a given host, bucketing the host label
+// to keep cardinality bounded.
+func (m *Metrics) ObserveOperation(operation, host string, seconds float64) {
+	m.observeHistogram("crawler_operation_duration_seconds", map[string]string{
+		"operation": operation,
+		"host":      hostBucket(host),
+	}, seconds)
+}
 
-	return m
+// hostBucket hashes a host into a small, fixed number of buckets so that the
+// "host" tag on per-operation histograms can't be used to create unbounded
+// series from attacker-controlled hostnames.
+func hostBucket(host string) string {
+	if host == "" {
+		return "unknown"
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(host))
+	return fmt.Sprintf("bucket_%02d", h.Sum32()%hostBuckets)
+}
+
+// IncrementConfigReload records the outcome ("success" or "failure") of a
+// single config.Manager hot-reload attempt.
+func (m *Metrics) IncrementConfigReload(outcome string) {
+	m.incrCounter("crawler_config_reloads_total", map[string]string{"outcome": outcome}, 1)
+}
+
+// IncrementRulesReload records the outcome ("success" or "failure") of a
+// single configwatch.Watcher rules-file reload attempt, separate from
+// IncrementConfigReload since the two watch independent files.
+func (m *Metrics) IncrementRulesReload(result string) {
+	m.incrCounter("crawler_rules_reloads_total", map[string]string{"result": result}, 1)
+}
+
+// IncrementPagesFailedReason breaks a failed page down by reason: "timeout",
+// "refused", "no_host", "http_error" or "other" as classified by
+// crawler.classifyFetchError, plus "pipeline" (a processor's PostResponse
+// failed) and "kafka" (publishing failed), which package crawler passes
+// directly. It's named crawler_pages_failed_reason_total
+// rather than reusing crawler_pages_failed_total because that name is
+// already registered above as an unlabeled CounterFunc backed directly by
+// PagesFailed; Prometheus can't register two collectors under the same name
+// with different label sets.
+func (m *Metrics) IncrementPagesFailedReason(reason string) {
+	m.incrCounter("crawler_pages_failed_reason_total", map[string]string{"reason": reason}, 1)
+}
+
+// SetQueueDepth reports the current size of one of the crawler's Redis-backed
+// queues ("pending", "requeued", or "visited"), polled periodically by
+// logMetricsPeriodically via Crawler.reportQueueDepths.
+func (m *Metrics) SetQueueDepth(queue string, depth int64) {
+	m.setGauge("crawler_queue_depth", map[string]string{"queue": queue}, float64(depth))
+}
+
+// SetKafkaBufferedRecords reports the current depth of KafkaPublisher's
+// in-memory buffer in async produce mode, so operators can watch it approach
+// KafkaMaxBufferedRecords before KafkaBufferFullPolicy kicks in.
+func (m *Metrics) SetKafkaBufferedRecords(n int64) {
+	m.setGauge("crawler_kafka_buffered_records", nil, float64(n))
+}
+
+// IncrementKafkaDropped counts records discarded by KafkaPublisher because
+// its buffer was full and KafkaBufferFullPolicy is "drop_oldest" or
+// "fail_fast" rejected the send outright.
+func (m *Metrics) IncrementKafkaDropped() {
+	m.incrCounter("crawler_kafka_dropped_total", nil, 1)
+}
+
+// IncrementCacheHit counts a membership lookup (set is "seen", "pending", or
+// "requeued") satisfied by the in-process LRU without falling through to Redis.
+func (m *Metrics) IncrementCacheHit(set string) {
+	m.incrCounter("crawler_cache_hits_total", map[string]string{"set": set}, 1)
+}
+
+// IncrementCacheMiss counts a membership lookup that missed the in-process
+// LRU and had to fall through to Redis.
+func (m *Metrics) IncrementCacheMiss(set string) {
+	m.incrCounter("crawler_cache_misses_total", map[string]string{"set": set}, 1)
+}
+
+// IncrementWARCRecords counts WARC records (kind is "request", "response", or
+// "warcinfo") successfully appended to the archive by warc.Writer.
+func (m *Metrics) IncrementWARCRecords(kind string) {
+	m.incrCounter("crawler_warc_records_total", map[string]string{"kind": kind}, 1)
+}
+
+// IncrementWARCErrors counts failures writing a WARC record, e.g. disk I/O
+// errors or a failed rotation to a new file.
+func (m *Metrics) IncrementWARCErrors() {
+	m.incrCounter("crawler_warc_errors_total", nil, 1)
 }
 
 // Uptime returns the time elapsed in seconds since the Metrics was initialized.
@@ -135,6 +328,25 @@ func (m *Metrics) Uptime() float64 {
 	return time.Since(m.startTime).Seconds()
 }
 
+// Close flushes and releases any sink resources that need it (e.g. the
+// StatsD sink's background flush loop and UDP socket). It's safe to call
+// even when every configured sink is a no-op for resources.
+func (m *Metrics) Close() error {
+	var firstErr error
+	for _, s := range m.sinks {
+		if closer, ok := s.(interface{ Close() error }); ok {
+			if err := closer.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if err := s.Flush(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
 // GetStats returns a snapshot of crawler metrics in a map format.
 func (m *Metrics) GetStats() map[string]any {
 	return map[string]any{
@@ -142,6 +354,7 @@ func (m *Metrics) GetStats() map[string]any {
 		"pages_processed":  m.GetPagesProcessed(),
 		"pages_successful": m.GetPagesSuccessful(),
 		"pages_failed":     m.GetPagesFailed(),
+		"pages_requeued":   m.GetPagesRequeued(),
 		"kafka_successful": m.GetKafkaSuccessful(),
 		"kafka_failed":     m.GetKafkaFailed(),
 		"kafka_errored":    m.GetKafkaErrored(),
@@ -151,23 +364,3 @@ func (m *Metrics) GetStats() map[string]any {
 		"uptime_seconds":   m.Uptime(),
 	}
 }
-
-// SyncPrometheusMetrics updates the Prometheus gauges with the current metrics values.
-// This function should be called periodically to ensure that Prometheus metrics are up-to-date.
-func (m *Metrics) SyncPrometheusMetrics() {
-	m.inflightPagesGauge.Set(float64(m.GetInflightPages()))
-
-	m.pagesProcessedGauge.Set(float64(m.GetPagesProcessed()))
-	m.pagesSuccessfulGauge.Set(float64(m.GetPagesSuccessful()))
-	m.pagesFailedGauge.Set(float64(m.GetPagesFailed()))
-
-	m.kafkaSuccessfulGauge.Set(float64(m.GetKafkaSuccessful()))
-	m.kafkaFailedGauge.Set(float64(m.GetKafkaFailed()))
-	m.kafkaErroredGauge.Set(float64(m.GetKafkaErrored()))
-
-	m.redisSuccessfulGauge.Set(float64(m.GetRedisSuccessful()))
-	m.redisFailedGauge.Set(float64(m.GetRedisFailed()))
-	m.redisErroredGauge.Set(float64(m.GetRedisErrored()))
-
-	m.uptimeGauge.Set(m.Uptime())
-}
@@ -0,0 +1,11 @@
+package metrics
+
+// noopSink discards every observation. It's selected via
+// METRICS_SINKS=noop, primarily so unit tests can construct a Metrics
+// without registering real Prometheus collectors or opening a UDP socket.
+type noopSink struct{}
+
+func (noopSink) IncrCounter(name string, tags map[string]string, delta int64)        {}
+func (noopSink) ObserveHistogram(name string, tags map[string]string, value float64) {}
+func (noopSink) SetGauge(name string, tags map[string]string, value float64)         {}
+func (noopSink) Flush() error                                                       { return nil }
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	// StdLib
+	"time"
+)
+
+// fetchOutcome classifies an HTTP status code into the "success"/"failed"/
+// "errored" outcome tag of crawler_pages_total (crawler_kafka_publish_total
+// sets only "success" or "errored", in ObserveKafkaPublish): 2xx is a success, a missing status (e.g. a
+// connection error, timeout, or DNS failure that never produced a response)
+// or 5xx is an error, and anything else (3xx/4xx) is a failure.
+func fetchOutcome(status int) string {
+	switch {
+	case status == 0 || status >= 500:
+		return "errored"
+	case status >= 200 && status < 300:
+		return "success"
+	default:
+		return "failed"
+	}
+}
+
+// ObservePageFetch records a single page fetch: it increments the
+// per-host/outcome page counter and observes the request duration and
+// response size histograms, all labeled by the fetched host's eTLD+1
+// (capped at trackedHosts distinct labels, see hostLimiter). status is the HTTP status code
+// returned for the request, or 0 if the request never got a response.
+func (m *Metrics) ObservePageFetch(host string, status int, dur time.Duration, bytes int) {
+	label := m.hosts.label(eTLD1(host))
+
+	m.incrCounter("crawler_pages_total", map[string]string{"host": label, "outcome": fetchOutcome(status)}, 1)
+	m.observeHistogram("crawler_request_duration_seconds", map[string]string{"host": label}, dur.Seconds())
+	if bytes > 0 {
+		m.observeHistogram("crawler_response_bytes", map[string]string{"host": label}, float64(bytes))
+	}
+}
+
+// ObserveKafkaPublish records a single attempt to hand a page off to the
+// configured message queue: it increments the per-host/outcome publish
+// counter and observes the publish latency histogram. For Kafka in async
+// mode (the default) this covers the initial enqueue, not final broker
+// acknowledgement, which is tracked separately via
+// IncrementKafkaSuccessful/Failed/Errored.
+func (m *Metrics) ObserveKafkaPublish(host string, dur time.Duration, err error) {
+	label := m.hosts.label(eTLD1(host))
+
+	outcome := "success"
+	if err != nil {
+		outcome = "errored"
+	}
+
+	m.incrCounter("crawler_kafka_publish_total", map[string]string{"host": label, "outcome": outcome}, 1)
+	m.observeHistogram("crawler_kafka_publish_seconds", map[string]string{"host": label}, dur.Seconds())
+}
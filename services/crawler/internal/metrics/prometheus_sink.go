@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	// StdLib
+	"sort"
+	"sync"
+
+	// Third-party
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// promSink is the Sink implementation backing the crawler's Prometheus
+// /metrics endpoint. Unlike the build-time-known CounterVec/HistogramVec
+// fields it replaced, Sink's name/tags are only known at call time, so
+// promSink lazily creates and registers one vec per distinct metric name
+// (keyed on its first-seen set of tag label names) and reuses it after that.
+type promSink struct {
+	defaultBuckets []float64
+	bucketsByName  map[string][]float64
+
+	mu         sync.Mutex
+	counters   map[string]*prometheus.CounterVec
+	histograms map[string]*prometheus.HistogramVec
+	gauges     map[string]*prometheus.GaugeVec
+}
+
+// newPromSink creates a promSink whose histograms use defaultBuckets, except
+// for any metric name present in bucketsByName, which uses that metric's own
+// bucket set instead. Passing nil for defaultBuckets falls back to
+// prometheus.DefBuckets; bucketsByName may be nil.
+func newPromSink(defaultBuckets []float64, bucketsByName map[string][]float64) *promSink {
+	if defaultBuckets == nil {
+		defaultBuckets = prometheus.DefBuckets
+	}
+	return &promSink{
+		defaultBuckets: defaultBuckets,
+		bucketsByName:  bucketsByName,
+		counters:       make(map[string]*prometheus.CounterVec),
+		histograms:     make(map[string]*prometheus.HistogramVec),
+		gauges:         make(map[string]*prometheus.GaugeVec),
+	}
+}
+
+// bucketsFor returns the bucket set to use when first creating the named
+// histogram.
+func (s *promSink) bucketsFor(name string) []float64 {
+	if b, ok := s.bucketsByName[name]; ok {
+		return b
+	}
+	return s.defaultBuckets
+}
+
+// labelNames returns tags' keys in sorted order, both for deterministic
+// Prometheus label registration and so WithLabelValues gets a stable order.
+func labelNames(tags map[string]string) []string {
+	names := make([]string, 0, len(tags))
+	for k := range tags {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func labelValues(names []string, tags map[string]string) []string {
+	values := make([]string, len(names))
+	for i, n := range names {
+		values[i] = tags[n]
+	}
+	return values
+}
+
+func (s *promSink) IncrCounter(name string, tags map[string]string, delta int64) {
+	names := labelNames(tags)
+
+	s.mu.Lock()
+	vec, ok := s.counters[name]
+	if !ok {
+		vec = prometheus.NewCounterVec(prometheus.CounterOpts{Name: name}, names)
+		prometheus.MustRegister(vec)
+		s.counters[name] = vec
+	}
+	s.mu.Unlock()
+
+	vec.WithLabelValues(labelValues(names, tags)...).Add(float64(delta))
+}
+
+func (s *promSink) ObserveHistogram(name string, tags map[string]string, value float64) {
+	names := labelNames(tags)
+
+	s.mu.Lock()
+	vec, ok := s.histograms[name]
+	if !ok {
+		vec = prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: name, Buckets: s.bucketsFor(name)}, names)
+		prometheus.MustRegister(vec)
+		s.histograms[name] = vec
+	}
+	s.mu.Unlock()
+
+	vec.WithLabelValues(labelValues(names, tags)...).Observe(value)
+}
+
+func (s *promSink) SetGauge(name string, tags map[string]string, value float64) {
+	names := labelNames(tags)
+
+	s.mu.Lock()
+	vec, ok := s.gauges[name]
+	if !ok {
+		vec = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name}, names)
+		prometheus.MustRegister(vec)
+		s.gauges[name] = vec
+	}
+	s.mu.Unlock()
+
+	vec.WithLabelValues(labelValues(names, tags)...).Set(value)
+}
+
+// Flush is a no-op: Prometheus is scraped, not pushed to.
+func (s *promSink) Flush() error { return nil }
@@ -17,6 +17,12 @@ func (m *Metrics) GetPagesSuccessful() int64 { return atomic.LoadInt64(&m.PagesS
 // Get pages that failed to process.
 func (m *Metrics) GetPagesFailed() int64 { return atomic.LoadInt64(&m.PagesFailed) }
 
+// Get pages that were re-queued after a retriable fetch or send failure.
+func (m *Metrics) GetPagesRequeued() int64 { return atomic.LoadInt64(&m.PagesRequeued) }
+
+// Get the count of URLs marked visited in this process's lifetime.
+func (m *Metrics) GetVisitedMarked() int64 { return atomic.LoadInt64(&m.VisitedMarked) }
+
 // Get pages that were successfully sent to Kafka.
 func (m *Metrics) GetKafkaSuccessful() int64 { return atomic.LoadInt64(&m.KafkaSuccessful) }
 
@@ -0,0 +1,24 @@
+package metrics
+
+// Sink is a destination for crawler metric observations. Metrics fans every
+// histogram/counter/gauge observation out to every configured Sink, so the
+// crawler can keep serving a pull-based Prometheus /metrics endpoint while
+// also pushing the same data into an existing StatsD pipeline, without
+// either implementation knowing about the other.
+//
+// name follows Prometheus naming convention (e.g. "crawler_pages_total");
+// sinks that target a different namespacing convention (StatsD) are
+// responsible for translating it themselves.
+type Sink interface {
+	// IncrCounter adds delta to the named counter, labeled by tags.
+	IncrCounter(name string, tags map[string]string, delta int64)
+	// ObserveHistogram records a single observation for the named
+	// histogram/timer, labeled by tags.
+	ObserveHistogram(name string, tags map[string]string, value float64)
+	// SetGauge sets the named gauge, labeled by tags, to value.
+	SetGauge(name string, tags map[string]string, value float64)
+	// Flush pushes any buffered observations to the backend. Sinks that
+	// send eagerly (e.g. Prometheus, which is scraped rather than pushed
+	// to) may treat this as a no-op.
+	Flush() error
+}
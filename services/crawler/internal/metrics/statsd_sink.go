@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	// StdLib
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// statsdSink pushes observations to a DogStatsD-compatible daemon over UDP,
+// in the "name:value|type|#tag1:val1,tag2:val2" wire format. Lines are
+// buffered and sent as a single packet per flush interval rather than one
+// packet per observation, since the crawler can easily produce thousands of
+// observations a second under load.
+type statsdSink struct {
+	prefix string
+	conn   net.Conn
+
+	mu  sync.Mutex
+	buf strings.Builder
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newStatsdSink dials a UDP "connection" to host:port (UDP is connectionless,
+// so this never blocks or fails on an unreachable daemon) and, if
+// flushInterval is positive, starts a background goroutine flushing the
+// buffer on that cadence.
+func newStatsdSink(host string, port int, prefix string, flushInterval time.Duration) (*statsdSink, error) {
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("dialing statsd at %s: %w", addr, err)
+	}
+
+	s := &statsdSink{prefix: prefix, conn: conn, done: make(chan struct{})}
+
+	if flushInterval > 0 {
+		s.wg.Add(1)
+		go s.flushLoop(flushInterval)
+	}
+
+	return s, nil
+}
+
+func (s *statsdSink) flushLoop(interval time.Duration) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			_ = s.Flush()
+		case <-s.done:
+			_ = s.Flush()
+			return
+		}
+	}
+}
+
+func (s *statsdSink) metricName(name string) string {
+	if s.prefix == "" {
+		return name
+	}
+	return s.prefix + "." + name
+}
+
+// dogStatsDTags renders tags in DogStatsD's "#key:value,key:value" suffix
+// format, with keys sorted for deterministic output.
+func dogStatsDTags(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	names := labelNames(tags)
+	parts := make([]string, len(names))
+	for i, n := range names {
+		parts[i] = n + ":" + tags[n]
+	}
+	return "|#" + strings.Join(parts, ",")
+}
+
+func (s *statsdSink) write(line string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buf.WriteString(line)
+	s.buf.WriteByte('\n')
+}
+
+func (s *statsdSink) IncrCounter(name string, tags map[string]string, delta int64) {
+	s.write(fmt.Sprintf("%s:%d|c%s", s.metricName(name), delta, dogStatsDTags(tags)))
+}
+
+func (s *statsdSink) ObserveHistogram(name string, tags map[string]string, value float64) {
+	s.write(fmt.Sprintf("%s:%g|h%s", s.metricName(name), value, dogStatsDTags(tags)))
+}
+
+func (s *statsdSink) SetGauge(name string, tags map[string]string, value float64) {
+	s.write(fmt.Sprintf("%s:%g|g%s", s.metricName(name), value, dogStatsDTags(tags)))
+}
+
+// Flush sends any buffered metric lines to the StatsD daemon as a single UDP
+// packet and resets the buffer.
+func (s *statsdSink) Flush() error {
+	s.mu.Lock()
+	payload := s.buf.String()
+	s.buf.Reset()
+	s.mu.Unlock()
+
+	if payload == "" {
+		return nil
+	}
+
+	_, err := s.conn.Write([]byte(payload))
+	return err
+}
+
+// Close stops the background flush loop (if any), flushing any remaining
+// buffered metrics first, and closes the UDP socket.
+func (s *statsdSink) Close() error {
+	close(s.done)
+	s.wg.Wait()
+	flushErr := s.Flush()
+	closeErr := s.conn.Close()
+	if flushErr != nil {
+		return flushErr
+	}
+	return closeErr
+}
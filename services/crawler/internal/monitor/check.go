@@ -0,0 +1,79 @@
+package monitor
+
+import (
+	// Stdlib
+	"context"
+	"time"
+)
+
+// Check is a single named readiness probe. Fn reports nil for healthy, or
+// an error describing why it isn't. Registered on monitorServer.checks and
+// run concurrently by handleReadyz; tests can replace the whole list via
+// SetChecks to inject fakes without touching Redis or Kafka.
+type Check struct {
+	Name string
+	Fn   func(ctx context.Context) error
+}
+
+// CheckResult is a single Check's outcome, as reported in /readyz's JSON body.
+type CheckResult struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"` // "ok" or "error"
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// defaultChecks returns the standard readiness probes: Redis reachability,
+// the message queue publisher's own health check, a "feeder alive" check
+// confirming feedCollyFromRedisQueue is still ticking, and a "backlog"
+// check (see checkBacklog).
+func (ms *monitorServer) defaultChecks() []Check {
+	return []Check{
+		{Name: "redis", Fn: ms.checkRedisHealth},
+		{Name: "queue", Fn: ms.checkQueueHealth},
+		{Name: "feeder", Fn: ms.checkFeederAlive},
+		{Name: "backlog", Fn: ms.checkBacklog},
+	}
+}
+
+// SetChecks replaces the registered readiness checks wholesale, so tests can
+// swap in fakes (e.g. a Check that always errors) without a live Redis or
+// Kafka cluster.
+func (ms *monitorServer) SetChecks(checks []Check) {
+	ms.checks = checks
+}
+
+// runChecks runs every registered check concurrently, bounding each to ctx,
+// and returns one CheckResult per check in registration order (not
+// completion order, so the JSON body is deterministic across requests).
+func runChecks(ctx context.Context, checks []Check) []CheckResult {
+	results := make([]CheckResult, len(checks))
+	done := make(chan struct{}, len(checks))
+
+	for i, chk := range checks {
+		go func(i int, chk Check) {
+			defer func() { done <- struct{}{} }()
+
+			start := time.Now()
+			err := chk.Fn(ctx)
+			latency := time.Since(start)
+
+			result := CheckResult{
+				Name:      chk.Name,
+				Status:    "ok",
+				LatencyMS: latency.Milliseconds(),
+			}
+			if err != nil {
+				result.Status = "error"
+				result.Error = err.Error()
+			}
+			results[i] = result
+		}(i, chk)
+	}
+
+	for range checks {
+		<-done
+	}
+
+	return results
+}
@@ -6,10 +6,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	// Third-party
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
 )
 
 // handleHealth checks the health of the monitor server by verifying Redis and Kafka connectivity.
@@ -44,13 +46,13 @@ func (ms *monitorServer) handleHealth(w http.ResponseWriter, r *http.Request) {
 		healthStatus.Services["redis"] = "healthy"
 	}
 
-	// Check Kafka connectivity
-	if err := ms.checkKafkaHealth(); err != nil {
+	// Check message queue connectivity
+	if err := ms.checkQueueHealth(ctx); err != nil {
 		healthStatus.Status = "unhealthy"
-		healthStatus.Services["kafka"] = "unhealthy"
-		healthStatus.Errors = append(healthStatus.Errors, fmt.Sprintf("Kafka: %v", err))
+		healthStatus.Services["queue"] = "unhealthy"
+		healthStatus.Errors = append(healthStatus.Errors, fmt.Sprintf("Queue: %v", err))
 	} else {
-		healthStatus.Services["kafka"] = "healthy"
+		healthStatus.Services["queue"] = "healthy"
 	}
 
 	// Check application-specific health
@@ -78,9 +80,95 @@ func (ms *monitorServer) handleHealth(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// checkRedisHealth performs Redis connectivity check
+// handleLivez is a cheap Kubernetes-style liveness probe: it only confirms
+// the monitor server's own goroutine is alive and the crawler reference is
+// intact, without touching Redis or the message queue. A failure here means
+// the process should be restarted, not just taken out of rotation.
+func (ms *monitorServer) handleLivez(w http.ResponseWriter, r *http.Request) {
+	if ms.crawler == nil || ms.crawler.Stats == nil {
+		http.Error(w, "not alive", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok"))
+}
+
+// handleReadyz is a Kubernetes-style readiness probe: it runs every
+// registered Check (see check.go; ms.checks defaults to redis, queue,
+// feeder, and backlog) concurrently against a shared, request-scoped
+// context, and reports each one's name, status, latency, and error as
+// JSON. A failure here means the instance should be taken out of rotation
+// but not restarted.
+func (ms *monitorServer) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	results := runChecks(ctx, ms.checks)
+
+	ready := true
+	for _, res := range results {
+		if res.Status != "ok" {
+			ready = false
+			break
+		}
+	}
+
+	body := struct {
+		Ready     bool          `json:"ready"`
+		Timestamp time.Time     `json:"timestamp"`
+		Checks    []CheckResult `json:"checks"`
+	}{
+		Ready:     ready,
+		Timestamp: time.Now().UTC(),
+		Checks:    results,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	if !ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// checkFeederAlive reports an error if feedCollyFromRedisQueue hasn't
+// ticked in over feederStaleAfter, which would otherwise silently stall
+// crawling while every other subsystem still reports healthy.
+const feederStaleAfter = 30 * time.Second
+
+func (ms *monitorServer) checkFeederAlive(ctx context.Context) error {
+	last := atomic.LoadInt64(&ms.crawler.FeederLastTick)
+	since := time.Since(time.Unix(0, last))
+	if since > feederStaleAfter {
+		return fmt.Errorf("no feeder tick in %s (last: %s)", since.Round(time.Second), time.Unix(0, last).UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// checkBacklog reports an error once in-flight pages exceed the configured
+// backpressure threshold, read live so a ReadinessBacklogThreshold change
+// from config.Manager takes effect without a restart.
+func (ms *monitorServer) checkBacklog(ctx context.Context) error {
+	threshold := ms.crawler.CfgManager.Current().ReadinessBacklogThreshold
+	if inflight := ms.crawler.Stats.GetInflightPages(); inflight > threshold {
+		return fmt.Errorf("backpressure: %d in-flight pages exceeds threshold %d", inflight, threshold)
+	}
+	return nil
+}
+
+// checkRedisHealth pings Redis. On a redis.ClusterClient this pings every
+// discovered shard (not just whichever node handles the default Ping
+// command), so a single unreachable node fails the check rather than being
+// masked by its healthy peers.
 func (ms *monitorServer) checkRedisHealth(ctx context.Context) error {
-	// Basic ping test
+	if cluster, ok := ms.crawler.RedisClient.(*redis.ClusterClient); ok {
+		return cluster.ForEachShard(ctx, func(ctx context.Context, shard *redis.Client) error {
+			return shard.Ping(ctx).Err()
+		})
+	}
+
 	if err := ms.crawler.RedisClient.Ping(ctx).Err(); err != nil {
 		return fmt.Errorf("ping failed: %w", err)
 	}
@@ -88,14 +176,14 @@ func (ms *monitorServer) checkRedisHealth(ctx context.Context) error {
 	return nil
 }
 
-// checkKafkaHealth performs Kafka connectivity and producer health check
-func (ms *monitorServer) checkKafkaHealth() error {
-	// Check if AsyncProducer is initialized
-	if ms.crawler.AsyncProducer == nil {
-		return fmt.Errorf("AsyncProducer not initialized")
+// checkQueueHealth delegates to the configured message queue publisher's
+// own health check (Kafka producer initialization, RabbitMQ connection state, etc.).
+func (ms *monitorServer) checkQueueHealth(ctx context.Context) error {
+	if ms.crawler.Publisher == nil {
+		return fmt.Errorf("queue publisher not initialized")
 	}
 
-	return nil
+	return ms.crawler.Publisher.HealthCheck(ctx)
 }
 
 // checkApplicationHealth performs application-specific health checks
@@ -126,11 +214,19 @@ func (ms *monitorServer) checkApplicationHealth() error {
 	return nil
 }
 
-// handleMetrics retrieves and returns the current metrics in Prometheus format.
-// It responds with HTTP 200 OK and the metrics in JSON format.
-// If there is an error encoding the metrics, it responds with HTTP 500 Internal Server Error.
+// handleMetrics exposes crawler metrics in Prometheus text exposition format.
+// Counters and gauges are backed directly by the crawler's atomic statistics,
+// so the scrape always reflects the current values.
 func (ms *monitorServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
-	ms.crawler.Stats.SyncPrometheusMetrics() // one-time sync before respond
-	// Delegate to the Prometheus HTTP handler
 	promhttp.Handler().ServeHTTP(w, r)
 }
+
+// handleBreakers reports the current circuit-breaker state ("closed",
+// "open", or "half_open") of every host the crawler has recorded a fetch
+// outcome for, so operators can see which hosts are being fail-fasted
+// without grepping logs.
+func (ms *monitorServer) handleBreakers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	_ = json.NewEncoder(w).Encode(ms.crawler.Breaker.States())
+}
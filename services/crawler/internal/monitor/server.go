@@ -3,16 +3,25 @@
 // operational visibility and integration with monitoring systems.
 //
 // The monitor server exposes:
-//   - /health: Health check endpoint for load balancers and orchestrators
+//   - /health, /healthz: Aggregate JSON health view for humans (identical;
+//     /healthz matches the more common probe-naming convention)
+//   - /livez: Kubernetes-style liveness probe (cheap, in-process only)
+//   - /readyz: Kubernetes-style readiness probe, running a pluggable list
+//     of Check functions (Redis, queue, feeder liveness, backlog) and
+//     returning each one's name/status/latency/error as JSON - see check.go
 //   - /metrics: Prometheus metrics endpoint for performance monitoring
+//   - /breakers: JSON snapshot of the per-host circuit breaker states
+//   - /urls: authenticated runtime URL submission, letting operators queue
+//     a URL without restarting the crawler with a new START_URLS - see urls.go
+//   - /debug/pprof/*: Go profiling endpoints, when EnableProfiling is set
 //
 // The server automatically starts when the crawler initializes and shuts down
-// gracefully when the crawler receives a shutdown signal. It performs periodic
-// synchronization of internal metrics to Prometheus gauges.
+// gracefully when the crawler receives a shutdown signal. Prometheus metrics are
+// backed directly by the crawler's atomic counters, so no periodic sync is needed.
 //
 // Health checks verify:
 //   - Redis connectivity and responsiveness
-//   - Kafka producer availability and channel responsiveness
+//   - Message queue publisher availability (Kafka or RabbitMQ)
 //   - Overall system health status
 //
 // This package is designed to be lightweight and non-intrusive to the main
@@ -24,6 +33,8 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/http/pprof"
+	"runtime"
 	"time"
 
 	// Internal modules
@@ -34,15 +45,22 @@ type monitorServer struct {
 	port       int          // Port for the monitor server
 	httpServer *http.Server // HTTP server instance
 	crawler    *crawler.Crawler
+	checks     []Check // Readiness probes run by handleReadyz; see check.go
+
+	apiTokens   map[string]bool     // Bearer tokens accepted by handleSubmitURL; empty disables the endpoint.
+	tokenScopes map[string][]string // token -> allowed hostnames for handleSubmitURL; absent entry means unscoped.
 }
 
 // Initialize the monitor server configuration
 func InitializeMonitorServer(crawler *crawler.Crawler) *monitorServer {
 	ms := &monitorServer{
-		port:       crawler.Cfg.MonitorPort,
-		httpServer: nil, // Will be set in Start function
-		crawler:    crawler,
+		port:        crawler.Cfg.MonitorPort,
+		httpServer:  nil, // Will be set in Start function
+		crawler:     crawler,
+		apiTokens:   parseAPITokens(crawler.Cfg.APITokens),
+		tokenScopes: parseTokenScopes(crawler.Cfg.APITokenScopes),
 	}
+	ms.checks = ms.defaultChecks()
 
 	return ms
 }
@@ -51,7 +69,16 @@ func InitializeMonitorServer(crawler *crawler.Crawler) *monitorServer {
 func (ms *monitorServer) Start() {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/health", ms.handleHealth)
+	mux.HandleFunc("/healthz", ms.handleHealth)
+	mux.HandleFunc("/livez", ms.handleLivez)
+	mux.HandleFunc("/readyz", ms.handleReadyz)
 	mux.HandleFunc("/metrics", ms.handleMetrics)
+	mux.HandleFunc("/breakers", ms.handleBreakers)
+	mux.HandleFunc("/urls", ms.handleSubmitURL)
+
+	if ms.crawler.Cfg.EnableProfiling {
+		ms.registerPprof(mux)
+	}
 
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", ms.port),
@@ -62,21 +89,6 @@ func (ms *monitorServer) Start() {
 
 	ms.httpServer = server
 
-	go func() {
-		ticker := time.NewTicker(5 * time.Second)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ticker.C:
-				ms.crawler.Stats.SyncPrometheusMetrics() // Sync metrics periodically
-			case <-ms.crawler.CShutdown:
-				ms.crawler.Stats.SyncPrometheusMetrics() // Final sync on shutdown
-				return                                   // Exit the goroutine on shutdown signal
-			}
-		}
-	}()
-
 	ms.crawler.Wg.Add(1)
 	go func() {
 		defer ms.crawler.Wg.Done()
@@ -98,3 +110,28 @@ func (ms *monitorServer) Start() {
 		}
 	}()
 }
+
+// registerPprof mounts the standard net/http/pprof handlers under
+// /debug/pprof/*. Block and mutex profiling additionally require
+// EnableDebug, since the sampling rate applies process-wide and adds
+// overhead to every blocking operation and lock acquisition.
+func (ms *monitorServer) registerPprof(mux *http.ServeMux) {
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/pprof/goroutine", pprof.Handler("goroutine"))
+	mux.Handle("/debug/pprof/heap", pprof.Handler("heap"))
+	mux.Handle("/debug/pprof/allocs", pprof.Handler("allocs"))
+	mux.Handle("/debug/pprof/block", pprof.Handler("block"))
+	mux.Handle("/debug/pprof/mutex", pprof.Handler("mutex"))
+
+	if ms.crawler.Cfg.EnableDebug {
+		runtime.SetBlockProfileRate(1)
+		runtime.SetMutexProfileFraction(1)
+		ms.crawler.Log.Info("Block and mutex profiling enabled")
+	}
+
+	ms.crawler.Log.Info("pprof endpoints registered under /debug/pprof/")
+}
@@ -0,0 +1,133 @@
+package monitor
+
+import (
+	// Stdlib
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	// Internal modules
+	"github.com/sneakyhydra/sneakdex/crawler/internal/crawler"
+)
+
+// urlSubmission is the JSON body accepted by POST /urls.
+type urlSubmission struct {
+	URL   string `json:"url"`
+	Depth int    `json:"depth"`
+}
+
+// handleSubmitURL lets operators schedule a URL for crawling at runtime,
+// without restarting the crawler with a new START_URLS. Requests must carry
+// a bearer token listed in API_TOKENS; a token scoped via API_TOKEN_SCOPES
+// may only submit URLs on its allowed hostnames. The URL is pushed onto the
+// same Redis frontier as ms.crawler.AddToPending, so it's indistinguishable
+// from one the crawler discovered itself.
+func (ms *monitorServer) handleSubmitURL(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token, ok := bearerToken(r)
+	if !ok || !ms.apiTokens[token] {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var sub urlSubmission
+	if err := json.NewDecoder(r.Body).Decode(&sub); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	normalizedURL, valid := ms.crawler.UrlValidator.IsValidURL(sub.URL)
+	if !valid {
+		http.Error(w, fmt.Sprintf("invalid url: %s", sub.URL), http.StatusBadRequest)
+		return
+	}
+
+	if allowedHosts, scoped := ms.tokenScopes[token]; scoped {
+		parsed, err := url.Parse(normalizedURL)
+		if err != nil || !hostAllowed(parsed.Hostname(), allowedHosts) {
+			http.Error(w, "token is not scoped to this hostname", http.StatusForbidden)
+			return
+		}
+	}
+
+	if sub.Depth < 0 || sub.Depth > ms.crawler.Cfg.CrawlDepth {
+		http.Error(w, fmt.Sprintf("depth must be between 0 and %d", ms.crawler.Cfg.CrawlDepth), http.StatusBadRequest)
+		return
+	}
+
+	ms.crawler.AddToPending(ms.crawler.Ctx, crawler.QueueItem{URL: normalizedURL, Depth: sub.Depth})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"status": "queued",
+		"url":    normalizedURL,
+		"depth":  sub.Depth,
+	})
+}
+
+// bearerToken extracts the token from a "Bearer <token>" Authorization header.
+func bearerToken(r *http.Request) (string, bool) {
+	auth := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if !strings.HasPrefix(auth, prefix) {
+		return "", false
+	}
+	token := strings.TrimSpace(strings.TrimPrefix(auth, prefix))
+	return token, token != ""
+}
+
+// hostAllowed reports whether host matches one of allowedHosts exactly.
+func hostAllowed(host string, allowedHosts []string) bool {
+	host = strings.ToLower(host)
+	for _, allowed := range allowedHosts {
+		if host == strings.ToLower(allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAPITokens splits the comma-separated API_TOKENS value into a lookup
+// set. An empty result disables the /urls endpoint entirely.
+func parseAPITokens(raw string) map[string]bool {
+	tokens := make(map[string]bool)
+	for _, token := range strings.Split(raw, ",") {
+		token = strings.TrimSpace(token)
+		if token != "" {
+			tokens[token] = true
+		}
+	}
+	return tokens
+}
+
+// parseTokenScopes parses API_TOKEN_SCOPES ("token1:host-a|host-b,token2:host-c")
+// into a token -> allowed hostnames map. A token absent from the result is
+// unscoped and may submit any hostname.
+func parseTokenScopes(raw string) map[string][]string {
+	scopes := make(map[string][]string)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		token := strings.TrimSpace(parts[0])
+		hosts := strings.Split(parts[1], "|")
+		for _, host := range hosts {
+			if host = strings.TrimSpace(host); host != "" {
+				scopes[token] = append(scopes[token], host)
+			}
+		}
+	}
+	return scopes
+}
@@ -0,0 +1,48 @@
+package pipeline
+
+import (
+	// Stdlib
+	"path"
+	"strings"
+
+	// Third-party
+	"github.com/gocolly/colly/v2"
+)
+
+// skipExtensions are file types no registered Processor handles, so
+// fetching them would only waste a request. ".pdf" is deliberately absent
+// here - whether it should be skipped depends on whether the "pdf"
+// Processor is enabled, which NewExtensionFilter decides.
+var skipExtensions = map[string]struct{}{
+	".jpg": {}, ".jpeg": {}, ".png": {}, ".gif": {}, ".css": {}, ".js": {}, ".ico": {},
+	".svg": {}, ".woff": {}, ".ttf": {}, ".mp4": {}, ".mp3": {}, ".zip": {}, ".exe": {},
+}
+
+// ExtensionFilter skips requests whose URL path ends in a known
+// non-page extension (images, fonts, stylesheets, scripts, archives), plus
+// ".pdf" when no registered Processor would accept it.
+type ExtensionFilter struct {
+	// skipPDF is true when the "pdf" Processor isn't enabled, so a ".pdf"
+	// link would only be fetched and then discarded unhandled.
+	skipPDF bool
+}
+
+// NewExtensionFilter returns an ExtensionFilter for a pipeline in which the
+// "pdf" Processor is registered only when pdfEnabled is true; otherwise
+// ".pdf" links are filtered out the same as any other unhandled extension.
+func NewExtensionFilter(pdfEnabled bool) ExtensionFilter {
+	return ExtensionFilter{skipPDF: !pdfEnabled}
+}
+
+func (ExtensionFilter) Name() string { return "extension" }
+
+func (f ExtensionFilter) PreRequest(r *colly.Request) Decision {
+	ext := strings.ToLower(path.Ext(r.URL.Path))
+	if _, skip := skipExtensions[ext]; skip {
+		return Decision{Skip: true, Reason: "file extension " + ext}
+	}
+	if f.skipPDF && ext == ".pdf" {
+		return Decision{Skip: true, Reason: "file extension " + ext}
+	}
+	return Decision{}
+}
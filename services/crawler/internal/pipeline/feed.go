@@ -0,0 +1,72 @@
+package pipeline
+
+import (
+	// Stdlib
+	"encoding/xml"
+	"strings"
+
+	// Third-party
+	"github.com/gocolly/colly/v2"
+)
+
+// rssFeed and atomFeed are minimal structs covering only the item/entry
+// link fields FeedProcessor needs; encoding/xml ignores elements neither
+// struct names.
+type rssFeed struct {
+	Channel struct {
+		Items []struct {
+			Link string `xml:"link"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+type atomFeed struct {
+	Entries []struct {
+		Links []struct {
+			Href string `xml:"href,attr"`
+		} `xml:"link"`
+	} `xml:"entry"`
+}
+
+// FeedProcessor handles RSS and Atom responses, emitting every discovered
+// item/entry link as an ArtifactLink for the crawler to add to its
+// frontier; the feed document itself isn't published downstream. It's
+// registered after SitemapProcessor (see sitemap.go) since both accept
+// generic XML Content-Types.
+type FeedProcessor struct{}
+
+func (FeedProcessor) Name() string { return "feed" }
+
+func (FeedProcessor) Accepts(r *colly.Response) bool {
+	ct := r.Headers.Get("Content-Type")
+	return strings.Contains(ct, "application/rss+xml") ||
+		strings.Contains(ct, "application/atom+xml") ||
+		strings.Contains(ct, "application/xml") ||
+		strings.Contains(ct, "text/xml")
+}
+
+func (FeedProcessor) PostResponse(r *colly.Response) ([]Artifact, error) {
+	var artifacts []Artifact
+
+	var rss rssFeed
+	if err := xml.Unmarshal(r.Body, &rss); err == nil {
+		for _, item := range rss.Channel.Items {
+			if item.Link != "" {
+				artifacts = append(artifacts, Artifact{Kind: ArtifactLink, URL: item.Link})
+			}
+		}
+	}
+
+	var atom atomFeed
+	if err := xml.Unmarshal(r.Body, &atom); err == nil {
+		for _, entry := range atom.Entries {
+			for _, link := range entry.Links {
+				if link.Href != "" {
+					artifacts = append(artifacts, Artifact{Kind: ArtifactLink, URL: link.Href})
+				}
+			}
+		}
+	}
+
+	return artifacts, nil
+}
@@ -0,0 +1,29 @@
+package pipeline
+
+import (
+	// Stdlib
+	"strings"
+
+	// Third-party
+	"github.com/gocolly/colly/v2"
+)
+
+// HTMLProcessor handles text/html and application/xhtml+xml responses,
+// publishing the raw page body downstream unchanged - the crawler's
+// original, pre-pipeline behavior.
+type HTMLProcessor struct {
+	// Topic, if non-empty, overrides the publisher's default topic for
+	// every artifact this processor emits.
+	Topic string
+}
+
+func (HTMLProcessor) Name() string { return "html" }
+
+func (HTMLProcessor) Accepts(r *colly.Response) bool {
+	ct := r.Headers.Get("Content-Type")
+	return strings.Contains(ct, "text/html") || strings.Contains(ct, "application/xhtml+xml")
+}
+
+func (p HTMLProcessor) PostResponse(r *colly.Response) ([]Artifact, error) {
+	return []Artifact{{Kind: ArtifactContent, Body: r.Body, Topic: p.Topic}}, nil
+}
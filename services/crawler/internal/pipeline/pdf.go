@@ -0,0 +1,44 @@
+package pipeline
+
+import (
+	// Stdlib
+	"bytes"
+	"fmt"
+	"strings"
+
+	// Third-party
+	"github.com/gocolly/colly/v2"
+	"github.com/ledongthuc/pdf"
+)
+
+// PDFProcessor extracts plain text from application/pdf responses via
+// ledongthuc/pdf and publishes the extracted text downstream in place of
+// the raw PDF bytes.
+type PDFProcessor struct {
+	Topic string
+}
+
+func (PDFProcessor) Name() string { return "pdf" }
+
+func (PDFProcessor) Accepts(r *colly.Response) bool {
+	return strings.Contains(r.Headers.Get("Content-Type"), "application/pdf")
+}
+
+func (p PDFProcessor) PostResponse(r *colly.Response) ([]Artifact, error) {
+	reader, err := pdf.NewReader(bytes.NewReader(r.Body), int64(len(r.Body)))
+	if err != nil {
+		return nil, fmt.Errorf("opening PDF: %w", err)
+	}
+
+	textReader, err := reader.GetPlainText()
+	if err != nil {
+		return nil, fmt.Errorf("extracting PDF text: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(textReader); err != nil {
+		return nil, fmt.Errorf("reading extracted PDF text: %w", err)
+	}
+
+	return []Artifact{{Kind: ArtifactContent, Body: buf.Bytes(), Topic: p.Topic}}, nil
+}
@@ -0,0 +1,120 @@
+// Package pipeline replaces the crawler's hard-coded file-extension and
+// Content-Type checks with a configurable, ordered chain of request-time
+// Filters and response-time Processors, so a new content type (PDF, an RSS
+// feed, a sitemap, ...) can be added without touching Colly handlers in
+// package crawler. A Pipeline has two extension points:
+//
+//   - Filter.PreRequest runs for every outbound request, before Colly
+//     dispatches it, and can abort requests that are never worth fetching
+//     (e.g. by file extension).
+//   - Processor.PostResponse runs once a response has been matched to a
+//     registered Processor via Accepts, and turns the response into zero or
+//     more Artifacts: page content to publish downstream, or links
+//     discovered inside the response (an RSS feed's items, a sitemap's
+//     <loc> entries) to add back to the crawl frontier.
+//
+// Processors are tried in registration order; the first whose Accepts
+// returns true handles the response. A response no registered Processor
+// accepts is left for the caller to handle - package crawler marks it
+// visited and moves on, matching the crawler's original HTML-only
+// behavior for any other content type.
+package pipeline
+
+import (
+	// Third-party
+	"github.com/gocolly/colly/v2"
+)
+
+// Decision is the result of a Filter's PreRequest check.
+type Decision struct {
+	Skip   bool   // true aborts the request before Colly sends it.
+	Reason string // human-readable reason, logged by the caller when Skip is true.
+}
+
+// Filter decides whether a request is worth making at all, before Colly
+// dispatches it.
+type Filter interface {
+	Name() string
+	PreRequest(r *colly.Request) Decision
+}
+
+// ArtifactKind distinguishes the two things a Processor can hand back to
+// the crawler from a single response.
+type ArtifactKind string
+
+const (
+	// ArtifactContent is page content to publish downstream (e.g. to Kafka)
+	// and archive to WARC.
+	ArtifactContent ArtifactKind = "content"
+	// ArtifactLink is a URL discovered inside the response - an RSS/Atom
+	// item, or a sitemap <loc> - to add back to the crawl frontier.
+	ArtifactLink ArtifactKind = "link"
+)
+
+// Artifact is one unit of output from a Processor's PostResponse.
+type Artifact struct {
+	Kind ArtifactKind
+
+	// Body and Topic apply to ArtifactContent: Body is the content to
+	// publish, and Topic - if non-empty - overrides the publisher's
+	// default topic/queue for this artifact (see queue.TopicHeaderKey).
+	Body  []byte
+	Topic string
+
+	// URL applies to ArtifactLink: a link discovered in the response to
+	// validate and enqueue the same way any other discovered link is.
+	URL string
+}
+
+// Processor extracts Artifacts from a response it Accepts.
+type Processor interface {
+	Name() string
+	Accepts(r *colly.Response) bool
+	PostResponse(r *colly.Response) ([]Artifact, error)
+}
+
+// Pipeline is an ordered chain of Filters and Processors, built once at
+// startup by crawler.buildPipeline from the configured PIPELINE_PROCESSORS
+// list.
+type Pipeline struct {
+	filters    []Filter
+	processors []Processor
+}
+
+// New returns an empty Pipeline; callers register Filters/Processors via
+// AddFilter/AddProcessor before first use.
+func New() *Pipeline {
+	return &Pipeline{}
+}
+
+// AddFilter appends f to the end of the request-time filter chain.
+func (p *Pipeline) AddFilter(f Filter) {
+	p.filters = append(p.filters, f)
+}
+
+// AddProcessor appends proc to the end of the response-time processor chain.
+func (p *Pipeline) AddProcessor(proc Processor) {
+	p.processors = append(p.processors, proc)
+}
+
+// PreRequest runs every registered Filter in order, stopping at the first
+// one that wants to skip the request.
+func (p *Pipeline) PreRequest(r *colly.Request) Decision {
+	for _, f := range p.filters {
+		if d := f.PreRequest(r); d.Skip {
+			return d
+		}
+	}
+	return Decision{}
+}
+
+// Select returns the first registered Processor that Accepts r, or nil if
+// none do.
+func (p *Pipeline) Select(r *colly.Response) Processor {
+	for _, proc := range p.processors {
+		if proc.Accepts(r) {
+			return proc
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package pipeline
+
+import (
+	// Stdlib
+	"bytes"
+	"encoding/xml"
+
+	// Third-party
+	"github.com/gocolly/colly/v2"
+)
+
+// sitemapURLSet and sitemapIndex cover the two sitemap document shapes
+// defined by the sitemaps.org protocol: a plain <urlset> of page URLs, and
+// a <sitemapindex> pointing at further sitemap files.
+type sitemapURLSet struct {
+	URLs []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+}
+
+type sitemapIndex struct {
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// SitemapProcessor handles sitemap.xml and sitemap index documents,
+// emitting every <loc> - page URLs from a <urlset>, or further sitemap
+// files from a <sitemapindex> - as an ArtifactLink so the crawler expands
+// them into its frontier.
+//
+// It's registered ahead of FeedProcessor since both accept generic XML
+// Content-Types: sitemaps are told apart by their root element rather than
+// Content-Type or URL, since sitemap.xml is served with varying
+// Content-Types across sites.
+type SitemapProcessor struct{}
+
+func (SitemapProcessor) Name() string { return "sitemap" }
+
+func (SitemapProcessor) Accepts(r *colly.Response) bool {
+	return bytes.Contains(r.Body, []byte("<urlset")) || bytes.Contains(r.Body, []byte("<sitemapindex"))
+}
+
+func (SitemapProcessor) PostResponse(r *colly.Response) ([]Artifact, error) {
+	if bytes.Contains(r.Body, []byte("<sitemapindex")) {
+		var idx sitemapIndex
+		if err := xml.Unmarshal(r.Body, &idx); err != nil {
+			return nil, err
+		}
+
+		artifacts := make([]Artifact, 0, len(idx.Sitemaps))
+		for _, sm := range idx.Sitemaps {
+			if sm.Loc != "" {
+				artifacts = append(artifacts, Artifact{Kind: ArtifactLink, URL: sm.Loc})
+			}
+		}
+		return artifacts, nil
+	}
+
+	var set sitemapURLSet
+	if err := xml.Unmarshal(r.Body, &set); err != nil {
+		return nil, err
+	}
+
+	artifacts := make([]Artifact, 0, len(set.URLs))
+	for _, u := range set.URLs {
+		if u.Loc != "" {
+			artifacts = append(artifacts, Artifact{Kind: ArtifactLink, URL: u.Loc})
+		}
+	}
+	return artifacts, nil
+}
@@ -0,0 +1,24 @@
+package pipeline
+
+import (
+	// Stdlib
+	"strings"
+
+	// Third-party
+	"github.com/gocolly/colly/v2"
+)
+
+// PlainTextProcessor handles text/plain responses, publishing the body as-is.
+type PlainTextProcessor struct {
+	Topic string
+}
+
+func (PlainTextProcessor) Name() string { return "text" }
+
+func (PlainTextProcessor) Accepts(r *colly.Response) bool {
+	return strings.Contains(r.Headers.Get("Content-Type"), "text/plain")
+}
+
+func (p PlainTextProcessor) PostResponse(r *colly.Response) ([]Artifact, error) {
+	return []Artifact{{Kind: ArtifactContent, Body: r.Body, Topic: p.Topic}}, nil
+}
@@ -0,0 +1,545 @@
+package queue
+
+import (
+	// Stdlib
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	// Third-party
+	"github.com/IBM/sarama"
+	"github.com/sirupsen/logrus"
+
+	// Internal modules
+	"github.com/sneakyhydra/sneakdex/crawler/internal/config"
+	"github.com/sneakyhydra/sneakdex/crawler/internal/metrics"
+)
+
+// messageMetadata round-trips a message's routing key and headers through
+// Sarama's async Successes()/Errors() channels, since they only carry back
+// the original *sarama.ProducerMessage.
+type messageMetadata struct {
+	key     string
+	headers map[string]string
+}
+
+// bufferedRecord is one record waiting in KafkaPublisher's async send
+// buffer, along with the channel Publish is blocked on for its queue-level
+// (not broker-acknowledged) result.
+type bufferedRecord struct {
+	msg    *sarama.ProducerMessage
+	result chan error
+}
+
+// KafkaPublisher publishes crawled pages to a Kafka topic, either
+// synchronously (Publish blocks for broker acknowledgement) or
+// asynchronously through a bounded in-memory buffer drained by a background
+// goroutine, per cfg.KafkaProduceMode. The async path deliberately drains
+// using its own lifecycle context (kp.ctx) rather than the context passed
+// to Publish, so a cancelled per-request fetch context can't abort a record
+// that has already been accepted into the buffer.
+type KafkaPublisher struct {
+	log   *logrus.Logger
+	topic string
+	stats *metrics.Metrics
+
+	// brokers and clientConfig are retained (rather than just the
+	// producers) so HealthCheck can open a short-lived sarama.Client to
+	// confirm broker metadata is actually reachable, not just that the
+	// producer was constructed successfully at startup.
+	brokers      []string
+	clientConfig *sarama.Config
+
+	sync bool
+
+	syncProducer  sarama.SyncProducer
+	asyncProducer sarama.AsyncProducer
+
+	// buffer and bufferPolicy are only used in async mode. bufMu guards
+	// bufferLen and closed; the buffer channel is read both by the drain
+	// goroutine and, for the "drop_oldest" policy, by Publish itself.
+	buffer       chan bufferedRecord
+	bufferPolicy string
+	bufferLen    int64
+	closed       bool
+	bufMu        sync.Mutex
+
+	ctx       context.Context
+	ctxCancel context.CancelFunc
+	done      chan struct{}
+	wg        sync.WaitGroup
+
+	onSuccess func(key string, headers map[string]string)
+	onError   func(key string, headers map[string]string, err error, retriable bool)
+}
+
+// compressionCodec maps cfg.KafkaCompression to its Sarama equivalent.
+// cfg.Validate has already rejected any other value.
+func compressionCodec(name string) sarama.CompressionCodec {
+	switch name {
+	case "none":
+		return sarama.CompressionNone
+	case "gzip":
+		return sarama.CompressionGZIP
+	case "lz4":
+		return sarama.CompressionLZ4
+	case "zstd":
+		return sarama.CompressionZSTD
+	default: // "snappy"
+		return sarama.CompressionSnappy
+	}
+}
+
+// requiredAcks maps cfg.KafkaAcks to its Sarama equivalent. cfg.Validate has
+// already rejected any other value. "local" is accepted as a synonym for
+// "leader" (Sarama's own naming for WaitForLocal).
+func requiredAcks(name string) sarama.RequiredAcks {
+	switch name {
+	case "none":
+		return sarama.NoResponse
+	case "all":
+		return sarama.WaitForAll
+	default: // "leader", "local"
+		return sarama.WaitForLocal
+	}
+}
+
+// buildTLSConfig turns cfg's Kafka TLS fields into a *tls.Config, loading the
+// CA certificate (if any) and the client certificate/key pair (if both are
+// set, for mutual TLS). cfg.Validate has already rejected a cert without its
+// matching key.
+func buildTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: cfg.KafkaInsecureSkipVerify} //nolint:gosec // operator opt-in for local/test clusters
+
+	if cfg.KafkaCACert != "" {
+		pem, err := os.ReadFile(cfg.KafkaCACert)
+		if err != nil {
+			return nil, fmt.Errorf("reading kafka CA cert: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in kafka CA cert %s", cfg.KafkaCACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.KafkaClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.KafkaClientCert, cfg.KafkaClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("loading kafka client cert/key: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// applySASL wires cfg's Kafka SASL fields into kafkaConfig. cfg.Validate has
+// already rejected an unrecognized mechanism or one without credentials.
+func applySASL(kafkaConfig *sarama.Config, cfg *config.Config) error {
+	if cfg.KafkaSASLMechanism == "" {
+		return nil
+	}
+
+	kafkaConfig.Net.SASL.Enable = true
+	kafkaConfig.Net.SASL.User = cfg.KafkaSASLUser
+	kafkaConfig.Net.SASL.Password = cfg.KafkaSASLPassword
+
+	switch cfg.KafkaSASLMechanism {
+	case "PLAIN":
+		kafkaConfig.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+	case "SCRAM-SHA-256":
+		kafkaConfig.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA256
+		kafkaConfig.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+			return &scramClient{hashGeneratorFcn: sha256HashGeneratorFcn}
+		}
+	case "SCRAM-SHA-512":
+		kafkaConfig.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA512
+		kafkaConfig.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+			return &scramClient{hashGeneratorFcn: sha512HashGeneratorFcn}
+		}
+	default:
+		return fmt.Errorf("unsupported kafka SASL mechanism %q", cfg.KafkaSASLMechanism)
+	}
+
+	return nil
+}
+
+// NewKafkaPublisher connects to Kafka with exponential-backoff retries and,
+// in async mode ("kafka_produce_mode" default), starts the buffer-draining
+// goroutine and the handlers that drain the producer's Successes() and
+// Errors() channels. stats may be nil (e.g. in tests); it's used only to
+// report buffer depth and dropped-record counts.
+func NewKafkaPublisher(cfg *config.Config, log *logrus.Logger, stats *metrics.Metrics) (*KafkaPublisher, error) {
+	kafkaConfig := sarama.NewConfig()
+	kafkaConfig.Producer.RequiredAcks = requiredAcks(cfg.KafkaAcks)
+	kafkaConfig.Producer.Timeout = cfg.KafkaRequiredAcksTimeout
+	kafkaConfig.Producer.Retry.Max = cfg.KafkaRetryMax
+	kafkaConfig.Producer.Retry.Backoff = 100 * time.Millisecond
+	kafkaConfig.Producer.Return.Successes = true
+	kafkaConfig.Producer.Return.Errors = true
+	kafkaConfig.Net.DialTimeout = cfg.RequestTimeout
+	kafkaConfig.Metadata.RefreshFrequency = 10 * time.Minute
+	kafkaConfig.Producer.MaxMessageBytes = cfg.MaxContentSize
+	kafkaConfig.Producer.Compression = compressionCodec(cfg.KafkaCompression)
+	kafkaConfig.Producer.Flush.Frequency = 100 * time.Millisecond
+	kafkaConfig.Producer.Flush.Messages = 100
+	kafkaConfig.Producer.Flush.Bytes = 1024 * 1024 // 1MB
+
+	if cfg.KafkaTLSEnable {
+		tlsConfig, err := buildTLSConfig(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("configuring kafka TLS: %w", err)
+		}
+		kafkaConfig.Net.TLS.Enable = true
+		kafkaConfig.Net.TLS.Config = tlsConfig
+	}
+
+	if err := applySASL(kafkaConfig, cfg); err != nil {
+		return nil, fmt.Errorf("configuring kafka SASL: %w", err)
+	}
+
+	brokers := strings.Split(cfg.KafkaBrokers, ",")
+	isSync := cfg.KafkaProduceMode == "sync"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	kp := &KafkaPublisher{
+		log:          log,
+		topic:        cfg.KafkaTopic,
+		stats:        stats,
+		brokers:      brokers,
+		clientConfig: kafkaConfig,
+		sync:         isSync,
+		bufferPolicy: cfg.KafkaBufferFullPolicy,
+		ctx:          ctx,
+		ctxCancel:    cancel,
+		done:         make(chan struct{}),
+	}
+
+	var err error
+	for attempt := 1; attempt <= cfg.KafkaRetryMax; attempt++ {
+		if isSync {
+			kafkaConfig.Producer.Return.Successes = true
+			var sp sarama.SyncProducer
+			sp, err = sarama.NewSyncProducer(brokers, kafkaConfig)
+			if err == nil {
+				kp.syncProducer = sp
+			}
+		} else {
+			var ap sarama.AsyncProducer
+			ap, err = sarama.NewAsyncProducer(brokers, kafkaConfig)
+			if err == nil {
+				kp.asyncProducer = ap
+			}
+		}
+		if err == nil {
+			break
+		}
+		log.Warnf("Kafka producer initialization attempt %d/%d failed: %v", attempt, cfg.KafkaRetryMax, err)
+		if attempt < cfg.KafkaRetryMax {
+			backoff := time.Duration(math.Pow(2, float64(attempt))) * 100 * time.Millisecond
+			time.Sleep(backoff)
+		}
+	}
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to create kafka producer after %d attempts. please ensure kafka is running on %s", cfg.KafkaRetryMax, strings.Join(brokers, ","))
+	}
+
+	if isSync {
+		log.Info("Kafka SyncProducer initialized")
+		return kp, nil
+	}
+
+	kp.buffer = make(chan bufferedRecord, cfg.KafkaMaxBufferedRecords)
+	kp.startHandlers()
+	kp.startDrain()
+
+	log.Info("Kafka AsyncProducer initialized")
+	return kp, nil
+}
+
+// SetCallbacks registers the functions invoked once a previously queued
+// message is actually acknowledged (onSuccess) or fails (onError). It
+// implements queue.AsyncCallbackSetter. It has no effect in sync mode, since
+// Publish's return value already reflects the broker's acknowledgement.
+func (kp *KafkaPublisher) SetCallbacks(
+	onSuccess func(key string, headers map[string]string),
+	onError func(key string, headers map[string]string, err error, retriable bool),
+) {
+	kp.onSuccess = onSuccess
+	kp.onError = onError
+}
+
+// startHandlers launches the background goroutines that drain Sarama's
+// async Successes()/Errors() channels for the lifetime of the publisher.
+func (kp *KafkaPublisher) startHandlers() {
+	kp.wg.Add(2)
+	go func() {
+		defer kp.wg.Done()
+		for {
+			select {
+			case success, ok := <-kp.asyncProducer.Successes():
+				if !ok {
+					return
+				}
+				meta, _ := success.Metadata.(messageMetadata)
+				if kp.onSuccess != nil {
+					kp.onSuccess(meta.key, meta.headers)
+				}
+			case <-kp.done:
+				return
+			}
+		}
+	}()
+
+	go func() {
+		defer kp.wg.Done()
+		for {
+			select {
+			case prodErr, ok := <-kp.asyncProducer.Errors():
+				if !ok {
+					return
+				}
+				meta, _ := prodErr.Msg.Metadata.(messageMetadata)
+				retriable := strings.Contains(prodErr.Err.Error(), "connection refused") ||
+					strings.Contains(prodErr.Err.Error(), "no such host") ||
+					strings.Contains(prodErr.Err.Error(), "timeout")
+				if kp.onError != nil {
+					kp.onError(meta.key, meta.headers, prodErr.Err, retriable)
+				}
+			case <-kp.done:
+				return
+			}
+		}
+	}()
+}
+
+// startDrain launches the goroutine that moves records out of kp.buffer and
+// into the Sarama async producer's own input channel, using kp.ctx (the
+// publisher's lifecycle context) rather than any individual Publish call's
+// context, so an in-flight record survives its originating request being
+// cancelled.
+func (kp *KafkaPublisher) startDrain() {
+	kp.wg.Add(1)
+	go func() {
+		defer kp.wg.Done()
+		for {
+			select {
+			case rec, ok := <-kp.buffer:
+				if !ok {
+					return
+				}
+				kp.bufMu.Lock()
+				kp.bufferLen--
+				kp.reportBufferLen()
+				kp.bufMu.Unlock()
+
+				select {
+				case kp.asyncProducer.Input() <- rec.msg:
+					rec.result <- nil
+				case <-kp.ctx.Done():
+					rec.result <- &PublishError{Backend: "kafka", Retriable: true, Err: kp.ctx.Err()}
+				}
+			case <-kp.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// reportBufferLen pushes the buffer's current depth to stats, if configured.
+// Callers must hold bufMu.
+func (kp *KafkaPublisher) reportBufferLen() {
+	if kp.stats != nil {
+		kp.stats.SetKafkaBufferedRecords(kp.bufferLen)
+	}
+}
+
+func (kp *KafkaPublisher) buildMessage(key string, headers map[string]string, payload []byte) *sarama.ProducerMessage {
+	msg := &sarama.ProducerMessage{
+		Topic:     kp.topic,
+		Key:       sarama.StringEncoder(key),
+		Value:     sarama.ByteEncoder(payload),
+		Timestamp: time.Now(),
+		Metadata:  messageMetadata{key: key, headers: headers},
+	}
+	for k, v := range headers {
+		// TopicHeaderKey overrides the produced-to topic rather than being
+		// sent along as an actual Kafka record header.
+		if k == TopicHeaderKey {
+			msg.Topic = v
+			continue
+		}
+		msg.Headers = append(msg.Headers, sarama.RecordHeader{Key: []byte(k), Value: []byte(v)})
+	}
+	return msg
+}
+
+// Publish sends payload to Kafka, keyed by key for partitioning. In sync
+// mode it blocks until the broker acknowledges the record (per
+// cfg.KafkaAcks) or cfg.KafkaRequiredAcksTimeout/ctx expires. In async mode
+// it enqueues the record into KafkaPublisher's bounded in-memory buffer,
+// applying cfg.KafkaBufferFullPolicy if that buffer is full, and reports
+// final delivery later through the callbacks registered via SetCallbacks.
+func (kp *KafkaPublisher) Publish(ctx context.Context, key string, headers map[string]string, payload []byte) error {
+	msg := kp.buildMessage(key, headers, payload)
+
+	if kp.sync {
+		return kp.publishSync(ctx, msg)
+	}
+	return kp.publishAsync(ctx, msg)
+}
+
+func (kp *KafkaPublisher) publishSync(ctx context.Context, msg *sarama.ProducerMessage) error {
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := kp.syncProducer.SendMessage(msg)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return &PublishError{Backend: "kafka", Retriable: true, Err: err}
+		}
+		return nil
+	case <-ctx.Done():
+		return &PublishError{Backend: "kafka", Retriable: true, Err: ctx.Err()}
+	}
+}
+
+func (kp *KafkaPublisher) publishAsync(ctx context.Context, msg *sarama.ProducerMessage) error {
+	kp.bufMu.Lock()
+	if kp.closed {
+		kp.bufMu.Unlock()
+		return &PublishError{Backend: "kafka", Retriable: false, Err: fmt.Errorf("kafka publisher is closing")}
+	}
+	if int(kp.bufferLen) >= cap(kp.buffer) {
+		switch kp.bufferPolicy {
+		case "drop_oldest":
+			select {
+			case old := <-kp.buffer:
+				kp.bufferLen--
+				kp.reportBufferLen()
+				old.result <- &PublishError{Backend: "kafka", Retriable: false, Err: fmt.Errorf("dropped: buffer full")}
+			default:
+			}
+			if kp.stats != nil {
+				kp.stats.IncrementKafkaDropped()
+			}
+		case "fail_fast":
+			kp.bufMu.Unlock()
+			if kp.stats != nil {
+				kp.stats.IncrementKafkaDropped()
+			}
+			return &PublishError{Backend: "kafka", Retriable: true, Err: fmt.Errorf("kafka buffer full (%d records)", cap(kp.buffer))}
+		}
+		// "block" falls through to the blocking send below.
+	}
+	kp.bufMu.Unlock()
+
+	rec := bufferedRecord{msg: msg, result: make(chan error, 1)}
+
+	select {
+	case kp.buffer <- rec:
+		kp.bufMu.Lock()
+		kp.bufferLen++
+		kp.reportBufferLen()
+		kp.bufMu.Unlock()
+	case <-ctx.Done():
+		return &PublishError{Backend: "kafka", Retriable: true, Err: ctx.Err()}
+	case <-kp.ctx.Done():
+		return &PublishError{Backend: "kafka", Retriable: true, Err: kp.ctx.Err()}
+	}
+
+	select {
+	case err := <-rec.result:
+		return err
+	case <-ctx.Done():
+		// The record is already in the buffer/producer pipeline and will
+		// still be delivered (or reported via callbacks); only this call's
+		// wait is abandoned.
+		return nil
+	}
+}
+
+// HealthCheck confirms the configured producer was constructed and that
+// KafkaBrokers actually answers a metadata request for kp.topic, by opening
+// a short-lived sarama.Client (cheaper than a full producer) and closing it
+// again. A stale DNS entry or a broker that's up but partitioned from the
+// rest of the cluster will fail here even though the long-lived producer
+// looked fine at startup.
+func (kp *KafkaPublisher) HealthCheck(ctx context.Context) error {
+	if kp.sync && kp.syncProducer == nil {
+		return fmt.Errorf("kafka producer not initialized")
+	}
+	if !kp.sync && kp.asyncProducer == nil {
+		return fmt.Errorf("kafka producer not initialized")
+	}
+
+	clientConfig := *kp.clientConfig
+	if deadline, ok := ctx.Deadline(); ok {
+		clientConfig.Net.DialTimeout = time.Until(deadline)
+	}
+
+	client, err := sarama.NewClient(kp.brokers, &clientConfig)
+	if err != nil {
+		return fmt.Errorf("connecting to kafka brokers: %w", err)
+	}
+	defer client.Close()
+
+	if err := client.RefreshMetadata(kp.topic); err != nil {
+		return fmt.Errorf("fetching kafka metadata for topic %q: %w", kp.topic, err)
+	}
+
+	return nil
+}
+
+// closeDrainDeadline bounds how long Close waits for startDrain to empty
+// kp.buffer into the Sarama producer before giving up on the rest.
+const closeDrainDeadline = 5 * time.Second
+
+// Close stops new records from being accepted, gives the drain goroutine
+// (async mode only) a bounded deadline to flush whatever's already buffered
+// into the Sarama producer, then stops the background handlers and closes
+// the underlying producer. Records still sitting in the buffer once the
+// deadline passes are abandoned (their Publish callers, if still waiting,
+// were already unblocked by their own ctx).
+func (kp *KafkaPublisher) Close() error {
+	if kp.sync {
+		close(kp.done)
+		return kp.syncProducer.Close()
+	}
+
+	kp.bufMu.Lock()
+	kp.closed = true
+	kp.bufMu.Unlock()
+
+	deadline := time.After(closeDrainDeadline)
+waitDrain:
+	for {
+		kp.bufMu.Lock()
+		empty := kp.bufferLen == 0
+		kp.bufMu.Unlock()
+		if empty {
+			break
+		}
+		select {
+		case <-time.After(20 * time.Millisecond):
+		case <-deadline:
+			break waitDrain
+		}
+	}
+
+	kp.ctxCancel()
+	close(kp.done)
+	kp.wg.Wait()
+	return kp.asyncProducer.Close()
+}
@@ -0,0 +1,58 @@
+// Package queue abstracts the message-queue backend the crawler publishes
+// crawled pages to. It was introduced so the crawler, health checks, and
+// monitoring code only ever depend on the Publisher interface, letting
+// operators choose Kafka or RabbitMQ via QUEUE_BACKEND without touching
+// pipeline code.
+package queue
+
+import (
+	// Stdlib
+	"context"
+	"fmt"
+)
+
+// TopicHeaderKey is a reserved headers entry that, if present, overrides the
+// Kafka topic a message is produced to (see KafkaPublisher.buildMessage);
+// it's stripped out of the headers actually sent as Kafka record headers.
+// pipeline.Processor implementations in package crawler set it when a
+// processor's config carries its own topic override (see
+// crawler.buildPipeline). RabbitMQPublisher has no per-message topic
+// concept and passes it through as an ordinary AMQP header.
+const TopicHeaderKey = "_topic"
+
+// Publisher sends crawled page payloads to a downstream queue for parsing.
+// key is used for partitioning/routing (the crawler passes the page URL);
+// headers carries out-of-band metadata (e.g. crawl depth, or a topic
+// override under TopicHeaderKey).
+type Publisher interface {
+	Publish(ctx context.Context, key string, headers map[string]string, payload []byte) error
+	HealthCheck(ctx context.Context) error
+	Close() error
+}
+
+// AsyncCallbackSetter is implemented by publishers whose Publish call only
+// enqueues a message without waiting for a broker acknowledgment (Kafka's
+// async producer, notably). Callers that need to react once delivery
+// actually succeeds or fails should type-assert for this interface.
+type AsyncCallbackSetter interface {
+	SetCallbacks(
+		onSuccess func(key string, headers map[string]string),
+		onError func(key string, headers map[string]string, err error, retriable bool),
+	)
+}
+
+// PublishError reports a Publish failure along with the backend that
+// produced it and whether the caller should retry.
+type PublishError struct {
+	Backend   string
+	Retriable bool
+	Err       error
+}
+
+func (e *PublishError) Error() string {
+	return fmt.Sprintf("%s publish failed (retriable: %v): %v", e.Backend, e.Retriable, e.Err)
+}
+
+func (e *PublishError) Unwrap() error {
+	return e.Err
+}
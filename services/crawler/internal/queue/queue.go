@@ -0,0 +1,29 @@
+package queue
+
+import (
+	// Stdlib
+	"fmt"
+
+	// Third-party
+	"github.com/sirupsen/logrus"
+
+	// Internal modules
+	"github.com/sneakyhydra/sneakdex/crawler/internal/config"
+	"github.com/sneakyhydra/sneakdex/crawler/internal/metrics"
+)
+
+// New constructs the Publisher selected by cfg.QueueBackend ("kafka" or
+// "rabbitmq"). cfg.Validate has already rejected any other value, so an
+// unexpected backend here indicates a config/validation mismatch. stats is
+// only consumed by KafkaPublisher, to report its in-memory buffer depth; it
+// may be nil in tests.
+func New(cfg *config.Config, log *logrus.Logger, stats *metrics.Metrics) (Publisher, error) {
+	switch cfg.QueueBackend {
+	case "kafka":
+		return NewKafkaPublisher(cfg, log, stats)
+	case "rabbitmq":
+		return NewRabbitMQPublisher(cfg, log)
+	default:
+		return nil, fmt.Errorf("unsupported QUEUE_BACKEND %q (expected kafka or rabbitmq)", cfg.QueueBackend)
+	}
+}
@@ -0,0 +1,124 @@
+package queue
+
+import (
+	// Stdlib
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	// Third-party
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/sirupsen/logrus"
+
+	// Internal modules
+	"github.com/sneakyhydra/sneakdex/crawler/internal/config"
+)
+
+// rabbitEnvelope is the JSON body published to RabbitMQ, mirroring the
+// (url, html) pair Kafka carries as a (key, value) message.
+type rabbitEnvelope struct {
+	URL  string `json:"url"`
+	HTML string `json:"html"`
+}
+
+// RabbitMQPublisher publishes crawled pages as JSON envelopes to a
+// configured exchange/routing key, for deployments built around an
+// existing AMQP scraping stack rather than Kafka.
+type RabbitMQPublisher struct {
+	log      *logrus.Logger
+	conn     *amqp.Connection
+	channel  *amqp.Channel
+	exchange string
+	routing  string
+}
+
+// NewRabbitMQPublisher dials the configured AMQP URI and declares the
+// configured exchange/queue/binding so Publish can start immediately.
+func NewRabbitMQPublisher(cfg *config.Config, log *logrus.Logger) (*RabbitMQPublisher, error) {
+	conn, err := amqp.Dial(cfg.RabbitMQURI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to open RabbitMQ channel: %w", err)
+	}
+
+	if err := ch.ExchangeDeclare(cfg.RabbitMQExchange, amqp.ExchangeDirect, true, false, false, false, nil); err != nil {
+		_ = ch.Close()
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to declare RabbitMQ exchange %q: %w", cfg.RabbitMQExchange, err)
+	}
+
+	if _, err := ch.QueueDeclare(cfg.RabbitMQQueue, true, false, false, false, nil); err != nil {
+		_ = ch.Close()
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to declare RabbitMQ queue %q: %w", cfg.RabbitMQQueue, err)
+	}
+
+	if err := ch.QueueBind(cfg.RabbitMQQueue, cfg.RabbitMQRoutingKey, cfg.RabbitMQExchange, false, nil); err != nil {
+		_ = ch.Close()
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to bind RabbitMQ queue %q to exchange %q: %w", cfg.RabbitMQQueue, cfg.RabbitMQExchange, err)
+	}
+
+	log.Infof("RabbitMQ publisher connected (exchange=%s routing_key=%s queue=%s)", cfg.RabbitMQExchange, cfg.RabbitMQRoutingKey, cfg.RabbitMQQueue)
+
+	return &RabbitMQPublisher{
+		log:      log,
+		conn:     conn,
+		channel:  ch,
+		exchange: cfg.RabbitMQExchange,
+		routing:  cfg.RabbitMQRoutingKey,
+	}, nil
+}
+
+// Publish wraps payload as {"url": key, "html": payload} and publishes it
+// to the configured exchange/routing key, waiting synchronously for the
+// broker to accept it. headers are forwarded as AMQP message headers.
+func (rp *RabbitMQPublisher) Publish(ctx context.Context, key string, headers map[string]string, payload []byte) error {
+	body, err := json.Marshal(rabbitEnvelope{URL: key, HTML: string(payload)})
+	if err != nil {
+		return &PublishError{Backend: "rabbitmq", Retriable: false, Err: fmt.Errorf("failed to marshal envelope: %w", err)}
+	}
+
+	amqpHeaders := amqp.Table{}
+	for k, v := range headers {
+		amqpHeaders[k] = v
+	}
+
+	publishCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := rp.channel.PublishWithContext(publishCtx, rp.exchange, rp.routing, false, false, amqp.Publishing{
+		ContentType: "application/json",
+		Body:        body,
+		Headers:     amqpHeaders,
+		Timestamp:   time.Now(),
+	}); err != nil {
+		return &PublishError{Backend: "rabbitmq", Retriable: true, Err: err}
+	}
+
+	return nil
+}
+
+// HealthCheck reports whether the AMQP connection is still open.
+func (rp *RabbitMQPublisher) HealthCheck(ctx context.Context) error {
+	if rp.conn == nil || rp.conn.IsClosed() {
+		return fmt.Errorf("RabbitMQ connection is closed")
+	}
+	return nil
+}
+
+// Close closes the channel and connection to RabbitMQ.
+func (rp *RabbitMQPublisher) Close() error {
+	chErr := rp.channel.Close()
+	connErr := rp.conn.Close()
+	if chErr != nil {
+		return fmt.Errorf("failed to close RabbitMQ channel: %w", chErr)
+	}
+	return connErr
+}
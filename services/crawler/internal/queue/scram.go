@@ -0,0 +1,44 @@
+package queue
+
+import (
+	// Third-party
+	"github.com/xdg-go/scram"
+)
+
+// sha256HashGeneratorFcn and sha512HashGeneratorFcn back the SCRAM-SHA-256
+// and SCRAM-SHA-512 mechanisms respectively; applySASL picks one based on
+// cfg.KafkaSASLMechanism.
+var (
+	sha256HashGeneratorFcn = scram.SHA256
+	sha512HashGeneratorFcn = scram.SHA512
+)
+
+// scramClient implements sarama.SCRAMClient on top of xdg-go/scram, following
+// the pattern documented in Sarama's own examples/sasl_scram_client.go.
+type scramClient struct {
+	*scram.ClientConversation
+	hashGeneratorFcn scram.HashGeneratorFcn
+}
+
+// Begin initializes the SCRAM conversation for the given credentials. Sarama
+// calls this once per connection before exchanging Step messages.
+func (c *scramClient) Begin(userName, password, authzID string) error {
+	client, err := c.hashGeneratorFcn.NewClient(userName, password, authzID)
+	if err != nil {
+		return err
+	}
+	c.ClientConversation = client.NewConversation()
+	return nil
+}
+
+// Step advances the SCRAM conversation by one round-trip, taking the
+// broker's last challenge (empty on the first call) and returning this
+// client's response.
+func (c *scramClient) Step(challenge string) (string, error) {
+	return c.ClientConversation.Step(challenge)
+}
+
+// Done reports whether the SCRAM conversation has completed.
+func (c *scramClient) Done() bool {
+	return c.ClientConversation.Done()
+}
@@ -0,0 +1,61 @@
+package retry
+
+import (
+	// Stdlib
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Classify decides whether a fetch failure is worth retrying. statusCode is
+// 0 if the request never got a response (network error); err is the Colly/
+// transport error in that case, and may be nil for a completed request that
+// simply returned a non-2xx status. retryAfter is the raw "Retry-After"
+// response header value, or "" if absent.
+//
+// Retriable: timeouts, connection refused, 5xx, 408, 429 (honoring
+// Retry-After if present), and any other failure with no status or a
+// non-error status. Terminal: every other 4xx, and DNS NXDOMAIN
+// ("no such host"), since no amount of retrying fixes a hostname that
+// doesn't resolve or a request the origin has permanently rejected.
+func Classify(statusCode int, err error, retryAfter string) (retriable bool, delay time.Duration) {
+	if err != nil {
+		msg := err.Error()
+		switch {
+		case strings.Contains(msg, "no such host"):
+			return false, 0
+		case strings.Contains(msg, "timeout"), strings.Contains(msg, "connection refused"):
+			return true, 0
+		}
+	}
+
+	switch {
+	case statusCode >= 500:
+		return true, 0
+	case statusCode == 429:
+		return true, parseRetryAfter(retryAfter)
+	case statusCode == 408:
+		return true, 0
+	case statusCode >= 400:
+		return false, 0
+	default:
+		// 0 (no response, uncategorized error) or a non-error status: treat
+		// as retriable so a transient, unlabeled failure still gets a
+		// second chance before MaxAttempts gives up on it.
+		return true, 0
+	}
+}
+
+// parseRetryAfter parses a "Retry-After" header value expressed in seconds
+// (the common case for 429s) and returns 0 if it's absent or malformed,
+// leaving the caller to fall back to its own backoff.
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	seconds, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,103 @@
+// Package retry tracks per-URL fetch/send attempt counts in Redis and
+// computes full-jitter exponential backoff delays for retriable failures,
+// independently of the crawler's own one-shot requeue path in storage.go.
+package retry
+
+import (
+	// Stdlib
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	// Third-party
+	"github.com/redis/go-redis/v9"
+)
+
+// attemptTTL bounds how long a retry:<url> counter survives in Redis once
+// incremented, so a URL that's eventually abandoned (MaxAttempts exhausted,
+// or never retried again) doesn't leave its counter around forever.
+const attemptTTL = 24 * time.Hour
+
+// Manager tracks per-URL retry attempt counts in Redis and computes backoff
+// delays for them. It holds no in-process state of its own - every call
+// round-trips to Redis - so it's safe to share across every Colly callback
+// goroutine without additional locking.
+type Manager struct {
+	redis       redis.UniversalClient
+	runID       string
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+}
+
+// NewManager creates a Manager backed by redisClient, namespacing every
+// counter it touches under runID the same way storage.go's key builders do.
+func NewManager(redisClient redis.UniversalClient, runID string, maxAttempts int, baseDelay, maxDelay time.Duration) *Manager {
+	return &Manager{
+		redis:       redisClient,
+		runID:       runID,
+		maxAttempts: maxAttempts,
+		baseDelay:   baseDelay,
+		maxDelay:    maxDelay,
+	}
+}
+
+// MaxAttempts returns the configured attempt ceiling; a caller should treat
+// the RecordAttempt result exceeding it as exhausted.
+func (m *Manager) MaxAttempts() int {
+	return m.maxAttempts
+}
+
+// key returns the Redis key holding url's attempt count for the current run.
+func (m *Manager) key(url string) string {
+	return fmt.Sprintf("crawler:{%s}:retry:%s", m.runID, url)
+}
+
+// RecordAttempt increments and returns url's attempt count, setting attemptTTL
+// on the key the first time it's created.
+func (m *Manager) RecordAttempt(ctx context.Context, url string) (int, error) {
+	key := m.key(url)
+
+	count, err := m.redis.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("incrementing retry count: %w", err)
+	}
+	if count == 1 {
+		if err := m.redis.Expire(ctx, key, attemptTTL).Err(); err != nil {
+			return int(count), fmt.Errorf("setting retry count TTL: %w", err)
+		}
+	}
+
+	return int(count), nil
+}
+
+// Reset clears url's attempt count, e.g. once it's finally handled
+// successfully, so a later resumed run doesn't inherit a stale count.
+func (m *Manager) Reset(ctx context.Context, url string) error {
+	return m.redis.Del(ctx, m.key(url)).Err()
+}
+
+// Backoff returns a full-jitter exponential backoff delay for the given
+// attempt number (1-indexed): rand(0, min(m.maxDelay, m.baseDelay*2^attempt)).
+func (m *Manager) Backoff(attempt int) time.Duration {
+	return fullJitterBackoff(attempt, m.baseDelay, m.maxDelay)
+}
+
+// fullJitterBackoff implements the "full jitter" strategy from
+// https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/:
+// rather than sleeping the full exponential delay (which synchronizes
+// retries across many URLs hitting the same host at once), it sleeps a
+// uniformly random duration between 0 and that delay.
+func fullJitterBackoff(attempt int, base, max time.Duration) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	capped := base * time.Duration(1<<uint(attempt))
+	if capped <= 0 || capped > max { // overflow or past the ceiling
+		capped = max
+	}
+
+	return time.Duration(rand.Int63n(int64(capped) + 1))
+}
@@ -0,0 +1,106 @@
+// Package tracing wires up OpenTelemetry instrumentation for the crawler,
+// exporting via OTLP/HTTP. Span names match the `Operation` field already
+// used by crawlerrors.CrawlError: package crawler opens a "Fetch" span per
+// request and a "Store" span per queue publish, each started from the
+// crawler's root context, so they are not grouped under a shared per-URL
+// span, and no "Parse" span is opened. Init registers the W3C tracecontext
+// and baggage propagators globally; trace context is not injected into the
+// headers of published messages.
+package tracing
+
+import (
+	// Stdlib
+	"context"
+	"fmt"
+
+	// Third-party
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+
+	// Internal modules
+	"github.com/sneakyhydra/sneakdex/crawler/internal/config"
+	"github.com/sneakyhydra/sneakdex/crawler/internal/crawlerrors"
+)
+
+// tracerName identifies this instrumentation library to the OpenTelemetry SDK.
+const tracerName = "github.com/sneakyhydra/sneakdex/crawler"
+
+// ShutdownFunc flushes and closes the tracer provider. It should be called
+// once, typically from the same graceful-shutdown path used elsewhere in the
+// crawler (see Crawler.Shutdown).
+type ShutdownFunc func(ctx context.Context) error
+
+// Init configures the global OpenTelemetry tracer provider from cfg and
+// returns a ShutdownFunc for graceful draining. If cfg.OTelExporterOTLP is
+// empty, tracing is configured with an always-off sampler so spans are
+// created (cheaply) but never exported, keeping call sites unconditional.
+func Init(ctx context.Context, cfg *config.Config) (ShutdownFunc, error) {
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceNameKey.String(cfg.OTelServiceName),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OpenTelemetry resource: %w", err)
+	}
+
+	sampler := sdktrace.TraceIDRatioBased(cfg.OTelSampleRatio)
+	if cfg.OTelExporterOTLP == "" {
+		sampler = sdktrace.NeverSample()
+	}
+
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sampler)),
+	}
+
+	if cfg.OTelExporterOTLP != "" {
+		exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(cfg.OTelExporterOTLP))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	tp := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return tp.Shutdown, nil
+}
+
+// StartOperation opens a child span named after a crawlerrors.CrawlError
+// Operation ("Fetch", "Parse", "Store", ...) for the given URL.
+func StartOperation(ctx context.Context, operation, url string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, operation, trace.WithAttributes(
+		attribute.String("url", url),
+	))
+}
+
+// RecordCrawlError finishes a span with the outcome of a pipeline operation,
+// recording the CrawlError's Retry/Timestamp as attributes and deriving the
+// span status from Unwrap() (the underlying, pre-wrapped error).
+func RecordCrawlError(span trace.Span, err *crawlerrors.CrawlError) {
+	if err == nil {
+		span.SetStatus(codes.Ok, "")
+		return
+	}
+
+	span.SetAttributes(
+		attribute.Bool("crawl.retry", err.Retry),
+		attribute.String("crawl.timestamp", err.Timestamp.Format("2006-01-02T15:04:05.000Z07:00")),
+	)
+	span.RecordError(err.Unwrap())
+	span.SetStatus(codes.Error, err.Unwrap().Error())
+}
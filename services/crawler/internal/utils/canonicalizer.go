@@ -0,0 +1,190 @@
+package utils
+
+import (
+	// Stdlib
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	// Third-party
+	"golang.org/x/net/idna"
+)
+
+// sessionIDPattern matches common session-identifier query parameter names
+// (sid, sessionid, phpsessid, jsessionid, ...) so they can be stripped even
+// when not explicitly listed in the denylist.
+var sessionIDPattern = regexp.MustCompile(`(?i)^(sid|session|session_id|sessionid|phpsessid|jsessionid)$`)
+
+// URLCanonicalizer implements the crawler's query-parameter canonicalization
+// policy: significant parameters are kept (per an optional per-host
+// allowlist), known tracking parameters are dropped (via a global denylist
+// and the built-in session-id pattern), and the remaining parameters are
+// sorted for a stable, dedup-friendly representation.
+type URLCanonicalizer struct {
+	// allowlist maps host -> set of query parameter names considered
+	// significant for that host. A host absent from this map has no
+	// allowlist restriction (denylist filtering still applies).
+	allowlist map[string]map[string]bool
+
+	denylistExact    map[string]bool
+	denylistPrefixes []string
+}
+
+// NewURLCanonicalizer builds a URLCanonicalizer from the
+// URL_QUERY_ALLOWLIST ("host1:param1|param2,host2:param3") and
+// URL_QUERY_DENYLIST ("utm_source,utm_*,fbclid,gclid") config values.
+// A denylist entry ending in "*" is matched as a prefix.
+func NewURLCanonicalizer(allowlistSpec, denylistSpec string) *URLCanonicalizer {
+	c := &URLCanonicalizer{
+		allowlist:     make(map[string]map[string]bool),
+		denylistExact: make(map[string]bool),
+	}
+
+	for _, entry := range strings.Split(allowlistSpec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		host := strings.ToLower(strings.TrimSpace(parts[0]))
+		params := make(map[string]bool)
+		for _, param := range strings.Split(parts[1], "|") {
+			if param = strings.TrimSpace(param); param != "" {
+				params[param] = true
+			}
+		}
+		if len(params) > 0 {
+			c.allowlist[host] = params
+		}
+	}
+
+	for _, entry := range strings.Split(denylistSpec, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if strings.HasSuffix(entry, "*") {
+			c.denylistPrefixes = append(c.denylistPrefixes, strings.TrimSuffix(entry, "*"))
+		} else {
+			c.denylistExact[entry] = true
+		}
+	}
+
+	return c
+}
+
+// isSignificant reports whether query parameter key should be kept for host,
+// applying the denylist (and built-in session-id pattern) first, then any
+// per-host allowlist.
+func (c *URLCanonicalizer) isSignificant(host, key string) bool {
+	lowerKey := strings.ToLower(key)
+
+	if c.denylistExact[lowerKey] || sessionIDPattern.MatchString(lowerKey) {
+		return false
+	}
+	for _, prefix := range c.denylistPrefixes {
+		if strings.HasPrefix(lowerKey, prefix) {
+			return false
+		}
+	}
+
+	if allowed, ok := c.allowlist[strings.ToLower(host)]; ok {
+		return allowed[key]
+	}
+
+	return true
+}
+
+// CanonicalizeQuery filters query per the denylist/allowlist policy for host
+// and returns the remaining parameters sorted by key (then value) as a
+// query string suitable for appending to a normalized URL.
+func (c *URLCanonicalizer) CanonicalizeQuery(host string, query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		if c.isSignificant(host, key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, url.QueryEscape(key)+"="+url.QueryEscape(value))
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+// CanonicalizeHost lowercases host and converts any internationalized
+// domain name to its ASCII punycode form (e.g. "münchen.de" ->
+// "xn--mnchen-3ya.de"), so visually distinct-but-equivalent hosts dedup
+// correctly.
+func (c *URLCanonicalizer) CanonicalizeHost(host string) (string, error) {
+	return idna.Lookup.ToASCII(strings.ToLower(host))
+}
+
+// CanonicalizePercentEncoding applies RFC 3986 §6.2.2.1: percent-encoded
+// octets that correspond to unreserved characters (ALPHA / DIGIT / "-" /
+// "." / "_" / "~") are decoded, and any remaining percent-encoded triplets
+// are uppercased. Go's net/url already does the equivalent for the path via
+// its own escape/unescape round-trip; this exists so the same rule can be
+// applied to a raw URL string - host and query included - before it's
+// parsed, so e.g. "%7Euser" and "~user" dedup to the same normalized URL.
+func CanonicalizePercentEncoding(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]) {
+			hi, lo := s[i+1], s[i+2]
+			decoded := hexVal(hi)<<4 | hexVal(lo)
+			if isUnreservedByte(decoded) {
+				b.WriteByte(decoded)
+			} else {
+				b.WriteByte('%')
+				b.WriteByte(upperHex(hi))
+				b.WriteByte(upperHex(lo))
+			}
+			i += 2
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func hexVal(c byte) byte {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0'
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+func upperHex(c byte) byte {
+	if c >= 'a' && c <= 'f' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
+
+func isUnreservedByte(b byte) bool {
+	return (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9') ||
+		b == '-' || b == '.' || b == '_' || b == '~'
+}
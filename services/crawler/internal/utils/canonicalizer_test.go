@@ -0,0 +1,166 @@
+package utils
+
+import (
+	// Stdlib
+	"net/url"
+	"testing"
+)
+
+// TestCanonicalizePercentEncoding covers the RFC 3986 §6.2.2.1 syntax-based
+// normalization examples: decoding percent-encoded unreserved octets, and
+// uppercasing the hex digits of any percent-encoding that's left alone.
+func TestCanonicalizePercentEncoding(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "unreserved tilde octet is decoded",
+			in:   "http://example.com/%7Euser",
+			want: "http://example.com/~user",
+		},
+		{
+			name: "unreserved alpha/digit octets are decoded",
+			in:   "%41%7a%30", // "A", "z", "0"
+			want: "Az0",
+		},
+		{
+			name: "reserved octet is kept percent-encoded but uppercased",
+			in:   "http://example.com/a%2fb",
+			want: "http://example.com/a%2Fb",
+		},
+		{
+			name: "already-uppercase reserved octet is unchanged",
+			in:   "http://example.com/a%2Fb",
+			want: "http://example.com/a%2Fb",
+		},
+		{
+			name: "mixed unreserved and reserved octets in one string",
+			in:   "%7Euser%2Fprofile%5F1",
+			want: "~user%2Fprofile_1",
+		},
+		{
+			name: "no percent-encoding is unchanged",
+			in:   "http://example.com/path?x=1",
+			want: "http://example.com/path?x=1",
+		},
+		{
+			name: "trailing percent without two hex digits is left alone",
+			in:   "http://example.com/100%",
+			want: "http://example.com/100%",
+		},
+		{
+			name: "percent followed by non-hex is left alone",
+			in:   "100%complete",
+			want: "100%complete",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanonicalizePercentEncoding(tt.in); got != tt.want {
+				t.Errorf("CanonicalizePercentEncoding(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestURLCanonicalizer_CanonicalizeQuery covers the allowlist/denylist query
+// policy: denylisted and session-id-shaped parameters are dropped, an
+// explicit per-host allowlist restricts to named parameters, a host absent
+// from the allowlist keeps everything the denylist doesn't drop, and the
+// surviving parameters come back alphabetically sorted.
+func TestURLCanonicalizer_CanonicalizeQuery(t *testing.T) {
+	tests := []struct {
+		name          string
+		allowlistSpec string
+		denylistSpec  string
+		host          string
+		query         string
+		want          string
+	}{
+		{
+			name:         "denylist drops exact and wildcard tracking params",
+			denylistSpec: "fbclid,utm_*",
+			host:         "example.com",
+			query:        "utm_source=ads&utm_campaign=x&fbclid=123&id=42",
+			want:         "id=42",
+		},
+		{
+			name:         "built-in session-id pattern is dropped even without denylist",
+			denylistSpec: "",
+			host:         "example.com",
+			query:        "PHPSESSID=abc&id=42",
+			want:         "id=42",
+		},
+		{
+			name:          "per-host allowlist restricts to named params",
+			allowlistSpec: "example.com:id|page",
+			host:          "example.com",
+			query:         "id=1&page=2&ref=homepage",
+			want:          "id=1&page=2",
+		},
+		{
+			name:          "allowlist only applies to the host it names",
+			allowlistSpec: "example.com:id",
+			host:          "other.com",
+			query:         "id=1&ref=homepage",
+			want:          "id=1&ref=homepage",
+		},
+		{
+			name:  "remaining params are sorted alphabetically by key",
+			host:  "example.com",
+			query: "zeta=1&alpha=2&mid=3",
+			want:  "alpha=2&mid=3&zeta=1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewURLCanonicalizer(tt.allowlistSpec, tt.denylistSpec)
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("parsing test query %q: %v", tt.query, err)
+			}
+			if got := c.CanonicalizeQuery(tt.host, query); got != tt.want {
+				t.Errorf("CanonicalizeQuery(%q, %q) = %q, want %q", tt.host, tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestURLCanonicalizer_CanonicalizeHost covers IDN-to-punycode host
+// normalization alongside plain lowercasing.
+func TestURLCanonicalizer_CanonicalizeHost(t *testing.T) {
+	tests := []struct {
+		name    string
+		host    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "ascii host is lowercased",
+			host: "EXAMPLE.com",
+			want: "example.com",
+		},
+		{
+			name: "internationalized domain is converted to punycode",
+			host: "münchen.de",
+			want: "xn--mnchen-3ya.de",
+		},
+	}
+
+	c := NewURLCanonicalizer("", "")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.CanonicalizeHost(tt.host)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CanonicalizeHost(%q) error = %v, wantErr %v", tt.host, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CanonicalizeHost(%q) = %q, want %q", tt.host, got, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,9 @@ package validator
 import (
 	// Stdlib
 	"time"
+
+	// Internal modules
+	"github.com/sneakyhydra/sneakdex/crawler/internal/utils"
 )
 
 // SetDNSCacheTimeout sets how long DNS results should be cached.
@@ -24,3 +27,22 @@ func (uv *URLValidator) SetAllowLoopback(allow bool) {
 func (uv *URLValidator) SetSkipDNSCheck(skip bool) {
 	uv.skipDNSCheck = skip
 }
+
+// SetCanonicalizer configures the query-parameter canonicalization policy
+// used during normalization. A nil canonicalizer (the default) preserves
+// the legacy behavior of dropping the query string entirely.
+func (uv *URLValidator) SetCanonicalizer(canon *utils.URLCanonicalizer) {
+	uv.canon = canon
+}
+
+// SetResolver configures the DNS resolver used for IP validation. A nil
+// resolver (the default) falls back to net.LookupIP via the OS resolver.
+func (uv *URLValidator) SetResolver(resolver Resolver) {
+	uv.resolver = resolver
+}
+
+// SetNegativeDNSCacheTimeout sets how long failed DNS lookups (NXDOMAIN,
+// SERVFAIL, etc.) are cached before being retried.
+func (uv *URLValidator) SetNegativeDNSCacheTimeout(timeout time.Duration) {
+	uv.negativeDNSCacheTTL = timeout
+}
@@ -2,6 +2,7 @@ package validator
 
 import (
 	// Stdlib
+	"context"
 	"net"
 	"time"
 
@@ -11,9 +12,25 @@ import (
 
 // DNSResult represents the result of a DNS resolution
 type DNSResult struct {
-	IPs       []net.IP  // Resolved IPs
-	Timestamp time.Time // When the DNS resolution occurred
-	Valid     bool      // Whether the DNS resolution succeeded
+	IPs       []net.IP      // Resolved IPs
+	Timestamp time.Time     // When the DNS resolution occurred
+	TTL       time.Duration // How long this entry should be cached (0 = use uv.dnsCacheTimeout, or uv.negativeDNSCacheTTL if !Valid)
+	Valid     bool          // Whether the DNS resolution succeeded
+}
+
+// expired reports whether a cached DNS result is past its TTL, falling back
+// to uv.dnsCacheTimeout for positive answers without a record TTL, and
+// uv.negativeDNSCacheTTL for cached failures (NXDOMAIN/SERVFAIL).
+func (uv *URLValidator) expired(result DNSResult) bool {
+	ttl := result.TTL
+	if ttl == 0 {
+		if result.Valid {
+			ttl = uv.dnsCacheTimeout
+		} else {
+			ttl = uv.negativeDNSCacheTTL
+		}
+	}
+	return time.Since(result.Timestamp) >= ttl
 }
 
 // isIPValid resolves a host to IPs (with caching) and checks if they are allowed.
@@ -27,7 +44,7 @@ func (uv *URLValidator) isIPValid(host string) bool {
 	// Case 2: Check DNS cache for host
 	if cached, exists := uv.dnsCache.Load(host); exists {
 		if result, ok := cached.(DNSResult); ok {
-			if time.Since(result.Timestamp) < uv.dnsCacheTimeout {
+			if !uv.expired(result) {
 				if !result.Valid {
 					uv.log.WithFields(logrus.Fields{
 						"host": host,
@@ -48,12 +65,20 @@ func (uv *URLValidator) isIPValid(host string) bool {
 	}
 
 	// Case 3: DNS lookup required
-	ips, err := net.LookupIP(host)
+	resolver := uv.resolver
+	if resolver == nil {
+		resolver = systemResolver{}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ips, ttl, err := resolver.LookupIP(ctx, host)
 
 	// Cache the result regardless of success
 	dnsResult := DNSResult{
 		IPs:       ips,
 		Timestamp: time.Now(),
+		TTL:       ttl,
 		Valid:     err == nil,
 	}
 	uv.dnsCache.Store(host, dnsResult)
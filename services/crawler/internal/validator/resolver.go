@@ -0,0 +1,249 @@
+package validator
+
+import (
+	// Stdlib
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	// Third-party
+	"github.com/miekg/dns"
+)
+
+// Resolver abstracts DNS resolution so URLValidator can use the OS resolver
+// or a DNS-over-HTTPS/TLS resolver interchangeably. ttl reports how long the
+// result should be cached, taken from the resolved record's own TTL; a zero
+// ttl means the caller should fall back to its own default.
+type Resolver interface {
+	LookupIP(ctx context.Context, host string) (ips []net.IP, ttl time.Duration, err error)
+}
+
+// systemResolver delegates to the OS resolver via net.DefaultResolver. It
+// doesn't have access to the record TTL, so it always reports a zero ttl.
+type systemResolver struct{}
+
+func (systemResolver) LookupIP(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+	ips, err := net.DefaultResolver.LookupIP(ctx, "ip", host)
+	return ips, 0, err
+}
+
+// dohResolver resolves hostnames using DNS-over-HTTPS (RFC 8484): a DNS
+// message is sent as the body of a POST request with
+// Content-Type: application/dns-message.
+type dohResolver struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewDoHResolver builds a DoH resolver against endpoint (e.g.
+// "https://cloudflare-dns.com/dns-query"). If bootstrapIPs is non-empty, the
+// endpoint's own hostname is resolved using those IPs instead of the system
+// resolver, avoiding a resolution chicken-and-egg problem in sandboxed
+// environments with no working default resolver.
+func NewDoHResolver(endpoint string, bootstrapIPs []string) *dohResolver {
+	transport := &http.Transport{}
+	if len(bootstrapIPs) > 0 {
+		dialer := &net.Dialer{Timeout: 5 * time.Second}
+		transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			_, port, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+			var lastErr error
+			for _, ip := range bootstrapIPs {
+				conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+				if err == nil {
+					return conn, nil
+				}
+				lastErr = err
+			}
+			return nil, lastErr
+		}
+	}
+
+	return &dohResolver{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 5 * time.Second, Transport: transport},
+	}
+}
+
+func (r *dohResolver) LookupIP(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+	ips4, ttl4, err4 := r.query(ctx, host, dns.TypeA)
+	ips6, ttl6, err6 := r.query(ctx, host, dns.TypeAAAA)
+
+	if err4 != nil && err6 != nil {
+		return nil, 0, err4
+	}
+
+	ips := append(ips4, ips6...)
+	ttl := ttl4
+	if ttl6 > 0 && (ttl == 0 || ttl6 < ttl) {
+		ttl = ttl6
+	}
+	return ips, ttl, nil
+}
+
+// query performs a single A or AAAA DoH query and returns the resolved IPs
+// along with the minimum TTL among the answer records.
+func (r *dohResolver) query(ctx context.Context, host string, qtype uint16) ([]net.IP, time.Duration, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(host), qtype)
+	msg.RecursionDesired = true
+
+	packed, err := msg.Pack()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to pack DNS query: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(packed))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to build DoH request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("DoH request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("DoH server returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read DoH response: %w", err)
+	}
+
+	reply := new(dns.Msg)
+	if err := reply.Unpack(body); err != nil {
+		return nil, 0, fmt.Errorf("failed to unpack DoH response: %w", err)
+	}
+
+	if reply.Rcode == dns.RcodeNameError {
+		return nil, 0, fmt.Errorf("NXDOMAIN for %s", host)
+	}
+	if reply.Rcode != dns.RcodeSuccess {
+		return nil, 0, fmt.Errorf("DoH query failed with rcode %s", dns.RcodeToString[reply.Rcode])
+	}
+
+	var ips []net.IP
+	var minTTL uint32
+	for _, rr := range reply.Answer {
+		var ip net.IP
+		switch record := rr.(type) {
+		case *dns.A:
+			ip = record.A
+		case *dns.AAAA:
+			ip = record.AAAA
+		default:
+			continue
+		}
+		ips = append(ips, ip)
+		if minTTL == 0 || rr.Header().Ttl < minTTL {
+			minTTL = rr.Header().Ttl
+		}
+	}
+
+	return ips, time.Duration(minTTL) * time.Second, nil
+}
+
+// dotResolver resolves hostnames using DNS-over-TLS (RFC 7858) via a plain
+// miekg/dns client dialing the resolver over TLS.
+type dotResolver struct {
+	addr   string
+	client *dns.Client
+}
+
+// NewDoTResolver builds a DoT resolver dialing addr (e.g.
+// "1.1.1.1:853").
+func NewDoTResolver(addr string) *dotResolver {
+	return &dotResolver{
+		addr:   addr,
+		client: &dns.Client{Net: "tcp-tls", Timeout: 5 * time.Second},
+	}
+}
+
+func (r *dotResolver) LookupIP(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+	ips4, ttl4, err4 := r.query(host, dns.TypeA)
+	ips6, ttl6, err6 := r.query(host, dns.TypeAAAA)
+
+	if err4 != nil && err6 != nil {
+		return nil, 0, err4
+	}
+
+	ips := append(ips4, ips6...)
+	ttl := ttl4
+	if ttl6 > 0 && (ttl == 0 || ttl6 < ttl) {
+		ttl = ttl6
+	}
+	return ips, ttl, nil
+}
+
+// NewResolverFromConfig builds a Resolver from the DNS_MODE/DNS_ENDPOINT/
+// DNS_BOOTSTRAP_IPS configuration values. mode "system" (or empty) returns
+// nil, meaning the caller should fall back to the OS resolver.
+func NewResolverFromConfig(mode, endpoint, bootstrapIPsRaw string) (Resolver, error) {
+	var bootstrapIPs []string
+	for _, ip := range strings.Split(bootstrapIPsRaw, ",") {
+		if ip = strings.TrimSpace(ip); ip != "" {
+			bootstrapIPs = append(bootstrapIPs, ip)
+		}
+	}
+
+	switch mode {
+	case "", "system":
+		return nil, nil
+	case "doh":
+		return NewDoHResolver(endpoint, bootstrapIPs), nil
+	case "dot":
+		return NewDoTResolver(endpoint), nil
+	default:
+		return nil, fmt.Errorf("unknown DNS mode: %s", mode)
+	}
+}
+
+func (r *dotResolver) query(host string, qtype uint16) ([]net.IP, time.Duration, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(host), qtype)
+	msg.RecursionDesired = true
+
+	reply, _, err := r.client.Exchange(msg, r.addr)
+	if err != nil {
+		return nil, 0, fmt.Errorf("DoT query failed: %w", err)
+	}
+
+	if reply.Rcode == dns.RcodeNameError {
+		return nil, 0, fmt.Errorf("NXDOMAIN for %s", host)
+	}
+	if reply.Rcode != dns.RcodeSuccess {
+		return nil, 0, fmt.Errorf("DoT query failed with rcode %s", dns.RcodeToString[reply.Rcode])
+	}
+
+	var ips []net.IP
+	var minTTL uint32
+	for _, rr := range reply.Answer {
+		var ip net.IP
+		switch record := rr.(type) {
+		case *dns.A:
+			ip = record.A
+		case *dns.AAAA:
+			ip = record.AAAA
+		default:
+			continue
+		}
+		ips = append(ips, ip)
+		if minTTL == 0 || rr.Header().Ttl < minTTL {
+			minTTL = rr.Header().Ttl
+		}
+	}
+
+	return ips, time.Duration(minTTL) * time.Second, nil
+}
@@ -10,6 +10,9 @@ import (
 
 	// Third-party
 	"github.com/sirupsen/logrus"
+
+	// Internal modules
+	"github.com/sneakyhydra/sneakdex/crawler/internal/utils"
 )
 
 // URLValidator provides methods to validate URLs against a whitelist/blacklist,
@@ -34,6 +37,15 @@ type URLValidator struct {
 	allowLoopback   bool
 	skipDNSCheck    bool
 	maxURLLength    int
+
+	// canon, if set, drives query-parameter canonicalization during
+	// normalization instead of dropping the query string outright.
+	canon *utils.URLCanonicalizer
+
+	// resolver, if set, is used for DNS lookups instead of net.LookupIP,
+	// allowing DNS-over-HTTPS/TLS resolution.
+	resolver            Resolver
+	negativeDNSCacheTTL time.Duration
 }
 
 // NewURLValidator initializes and returns a URLValidator with the given options
@@ -44,14 +56,15 @@ func NewURLValidator(whitelist, blacklist []string, log *logrus.Logger) *URLVali
 	}
 
 	newUrlValidator := &URLValidator{
-		whitelist:       whitelist,
-		blacklist:       blacklist,
-		log:             log,
-		dnsCacheTimeout: 5 * time.Minute,
-		allowPrivateIPs: false,
-		allowLoopback:   false,
-		skipDNSCheck:    true,
-		maxURLLength:    2048, // Default max URL length
+		whitelist:           whitelist,
+		blacklist:           blacklist,
+		log:                 log,
+		dnsCacheTimeout:     5 * time.Minute,
+		negativeDNSCacheTTL: 30 * time.Second,
+		allowPrivateIPs:     false,
+		allowLoopback:       false,
+		skipDNSCheck:        true,
+		maxURLLength:        2048, // Default max URL length
 	}
 
 	return newUrlValidator
@@ -63,8 +76,10 @@ func NewURLValidator(whitelist, blacklist []string, log *logrus.Logger) *URLVali
 // - Passes domain allow/block logic
 // - Passes IP validation from DNS resolution (if enabled)
 func (uv *URLValidator) IsValidURL(rawURL string) (string, bool) {
-	// Trim whitespace from the input URL
-	trimmedURL := strings.TrimSpace(rawURL)
+	// Trim whitespace from the input URL, then decode percent-encoded
+	// unreserved octets and uppercase the remaining escapes (RFC 3986
+	// §6.2.2.1) so "%7Euser" and "~user" dedup to the same cache entry.
+	trimmedURL := utils.CanonicalizePercentEncoding(strings.TrimSpace(rawURL))
 
 	// --- URL Length Check ---
 	if trimmedURL == "" || len(trimmedURL) > uv.maxURLLength {
@@ -146,7 +161,15 @@ func (uv *URLValidator) IsValidURL(rawURL string) (string, bool) {
 	parsedURL.Scheme = scheme // Already lowercase from validation
 	parsedURL.Host = host     // Already lowercase and cleaned
 	parsedURL.Fragment = ""   // Remove fragments (client-side navigation)
-	parsedURL.RawQuery = ""   // Remove query parameters (session IDs, tracking, etc.)
+
+	if uv.canon != nil {
+		if asciiHost, err := uv.canon.CanonicalizeHost(parsedURL.Host); err == nil {
+			parsedURL.Host = asciiHost
+		}
+		parsedURL.RawQuery = uv.canon.CanonicalizeQuery(parsedURL.Host, parsedURL.Query())
+	} else {
+		parsedURL.RawQuery = "" // Remove query parameters (session IDs, tracking, etc.)
+	}
 
 	// Normalize path for consistent URLs
 	parsedURL.Path = uv.normalizePath(parsedURL.Path)
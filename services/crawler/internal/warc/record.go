@@ -0,0 +1,151 @@
+package warc
+
+import (
+	// Stdlib
+	"crypto/sha1"
+	"encoding/base32"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	// Third-party
+	"github.com/google/uuid"
+)
+
+// newRecordID returns a fresh WARC-Record-ID as a UUID URN, e.g.
+// "<urn:uuid:6ba7b810-9dad-11d1-80b4-00c04fd430c8>".
+func newRecordID() string {
+	return fmt.Sprintf("<urn:uuid:%s>", uuid.NewString())
+}
+
+// payloadDigest computes the WARC-Payload-Digest of body: "sha1:" followed
+// by the base32 (RFC 4648, no padding) encoding of its SHA-1, matching the
+// convention used by Heritrix, wget --warc-file, and the Common Crawl corpus.
+func payloadDigest(body []byte) string {
+	sum := sha1.Sum(body)
+	return "sha1:" + base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(sum[:])
+}
+
+// formatHeader renders an http.Header as CRLF-terminated "Key: Value" lines,
+// sorted by key so record output is deterministic.
+func formatHeader(h http.Header) string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		for _, v := range h[k] {
+			b.WriteString(k)
+			b.WriteString(": ")
+			b.WriteString(v)
+			b.WriteString("\r\n")
+		}
+	}
+	return b.String()
+}
+
+// record is a single WARC record: a block of WARC-* and Content-* header
+// lines (warcHeaders, already CRLF-terminated per line but without the
+// trailing blank line) followed by an opaque content block.
+type record struct {
+	warcHeaders string
+	content     []byte
+}
+
+// bytes renders the record in WARC/1.1 wire format: the version line, the
+// header block, a blank line, the content block, and the mandatory trailing
+// "\r\n\r\n" record terminator.
+func (r record) bytes() []byte {
+	var b strings.Builder
+	b.WriteString("WARC/1.1\r\n")
+	b.WriteString(r.warcHeaders)
+	b.WriteString("\r\n")
+	b.Write(r.content)
+	b.WriteString("\r\n\r\n")
+	return []byte(b.String())
+}
+
+// newWARCInfoRecord builds the "warcinfo" record written at the head of
+// every WARC file, describing the crawler that produced it.
+func newWARCInfoRecord(software, format string) record {
+	body := fmt.Sprintf("software: %s\r\nformat: %s\r\n", software, format)
+
+	headers := fmt.Sprintf(
+		"WARC-Type: warcinfo\r\n"+
+			"WARC-Record-ID: %s\r\n"+
+			"WARC-Date: %s\r\n"+
+			"Content-Type: application/warc-fields\r\n"+
+			"Content-Length: %d\r\n",
+		newRecordID(), time.Now().UTC().Format(time.RFC3339), len(body),
+	)
+
+	return record{warcHeaders: headers, content: []byte(body)}
+}
+
+// newRequestRecord builds a "request" record for an outgoing HTTP request:
+// its request line, headers, and body (if any) as the content block.
+func newRequestRecord(targetURI, method string, reqHeaders http.Header, body []byte) record {
+	var content strings.Builder
+	fmt.Fprintf(&content, "%s %s HTTP/1.1\r\n", method, requestTarget(targetURI))
+	content.WriteString(formatHeader(reqHeaders))
+	content.WriteString("\r\n")
+	content.Write(body)
+
+	contentBytes := []byte(content.String())
+	headers := fmt.Sprintf(
+		"WARC-Type: request\r\n"+
+			"WARC-Record-ID: %s\r\n"+
+			"WARC-Date: %s\r\n"+
+			"WARC-Target-URI: %s\r\n"+
+			"Content-Type: application/http; msgtype=request\r\n"+
+			"Content-Length: %d\r\n"+
+			"WARC-Payload-Digest: %s\r\n",
+		newRecordID(), time.Now().UTC().Format(time.RFC3339), targetURI,
+		len(contentBytes), payloadDigest(body),
+	)
+
+	return record{warcHeaders: headers, content: contentBytes}
+}
+
+// newResponseRecord builds a "response" record for a fetched HTTP response:
+// its status line, headers, and body as the content block.
+func newResponseRecord(targetURI string, statusCode int, respHeaders http.Header, body []byte) record {
+	var content strings.Builder
+	fmt.Fprintf(&content, "HTTP/1.1 %d %s\r\n", statusCode, http.StatusText(statusCode))
+	content.WriteString(formatHeader(respHeaders))
+	content.WriteString("\r\n")
+	content.Write(body)
+
+	contentBytes := []byte(content.String())
+	headers := fmt.Sprintf(
+		"WARC-Type: response\r\n"+
+			"WARC-Record-ID: %s\r\n"+
+			"WARC-Date: %s\r\n"+
+			"WARC-Target-URI: %s\r\n"+
+			"Content-Type: application/http; msgtype=response\r\n"+
+			"Content-Length: %d\r\n"+
+			"WARC-Payload-Digest: %s\r\n",
+		newRecordID(), time.Now().UTC().Format(time.RFC3339), targetURI,
+		len(contentBytes), payloadDigest(body),
+	)
+
+	return record{warcHeaders: headers, content: contentBytes}
+}
+
+// requestTarget extracts the path?query portion of a full URL for use on
+// the HTTP request line, falling back to the full string if it can't be
+// parsed (the caller already validated it as a URL before fetching).
+func requestTarget(targetURI string) string {
+	if i := strings.Index(targetURI, "://"); i >= 0 {
+		if j := strings.Index(targetURI[i+3:], "/"); j >= 0 {
+			return targetURI[i+3+j:]
+		}
+		return "/"
+	}
+	return targetURI
+}
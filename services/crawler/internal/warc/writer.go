@@ -0,0 +1,175 @@
+// Package warc implements a minimal WARC/1.1 sink for archiving fetched
+// pages to disk, independent of (and optionally alongside) the Kafka/
+// RabbitMQ queue.Publisher path. Each request/response pair fetched by
+// Colly is written as a "request" record followed by a "response" record;
+// every output file opens with a "warcinfo" record describing the crawler.
+//
+// Records are gzipped individually (not the file as a whole), so a reader
+// can decompress and parse a WARC/GZ file as a concatenation of
+// independent gzip members - the format WARC tools expect - and a crash
+// mid-file doesn't corrupt records already flushed.
+package warc
+
+import (
+	// Stdlib
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	// Internal modules
+	"github.com/sneakyhydra/sneakdex/crawler/internal/config"
+	"github.com/sneakyhydra/sneakdex/crawler/internal/metrics"
+
+	// Third-party
+	"github.com/sirupsen/logrus"
+)
+
+// Writer appends WARC records to a rotating set of gzip-compressed WARC
+// files under cfg.WARCOutputDir. It is safe for concurrent use; Colly's
+// OnResponse handlers may call WritePage from multiple goroutines.
+type Writer struct {
+	mu sync.Mutex
+
+	cfg *config.Config
+	log *logrus.Logger
+	stats *metrics.Metrics
+
+	software string // reported in each file's warcinfo record
+
+	file    *os.File
+	written int64
+	seq     int
+}
+
+// New creates cfg.WARCOutputDir if needed and opens the first WARC file.
+// Returns nil, nil if cfg.WARCEnable is false, so callers can check for a
+// nil *Writer instead of threading an "enabled" bool through every call site.
+func New(cfg *config.Config, log *logrus.Logger, stats *metrics.Metrics) (*Writer, error) {
+	if !cfg.WARCEnable {
+		return nil, nil
+	}
+
+	if err := os.MkdirAll(cfg.WARCOutputDir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating WARC output dir %q: %w", cfg.WARCOutputDir, err)
+	}
+
+	w := &Writer{
+		cfg:      cfg,
+		log:      log,
+		stats:    stats,
+		software: fmt.Sprintf("sneakdex-crawler/%s (commit %s)", metrics.Version, metrics.Commit),
+	}
+
+	if err := w.rotate(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// WritePage appends a request record and a response record for a single
+// fetched page, rotating to a new file first if the current one would
+// exceed WARCMaxFileSizeMB.
+func (w *Writer) WritePage(targetURI, method string, reqHeaders http.Header, reqBody []byte, statusCode int, respHeaders http.Header, respBody []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	req := newRequestRecord(targetURI, method, reqHeaders, reqBody)
+	if err := w.writeRecordLocked(req, "request"); err != nil {
+		return err
+	}
+
+	resp := newResponseRecord(targetURI, statusCode, respHeaders, respBody)
+	if err := w.writeRecordLocked(resp, "response"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// writeRecordLocked gzips and appends a single record, rotating first if it
+// would push the current file over WARCMaxFileSizeMB. Callers must hold w.mu.
+func (w *Writer) writeRecordLocked(r record, kind string) error {
+	maxBytes := w.cfg.WARCMaxFileSizeMB * 1024 * 1024
+	if w.written > 0 && w.written+int64(len(r.content)) > maxBytes {
+		if err := w.rotate(); err != nil {
+			w.stats.IncrementWARCErrors()
+			return fmt.Errorf("rotating WARC file: %w", err)
+		}
+	}
+
+	n, err := w.writeGzipMember(r.bytes())
+	if err != nil {
+		w.stats.IncrementWARCErrors()
+		return fmt.Errorf("writing WARC %s record: %w", kind, err)
+	}
+
+	w.written += int64(n)
+	w.stats.IncrementWARCRecords(kind)
+	return nil
+}
+
+// writeGzipMember gzips data as its own independent gzip member and appends
+// it to the current file, returning len(data), the uncompressed size.
+func (w *Writer) writeGzipMember(data []byte) (int, error) {
+	gz := gzip.NewWriter(w.file)
+	if _, err := gz.Write(data); err != nil {
+		_ = gz.Close()
+		return 0, err
+	}
+	if err := gz.Close(); err != nil {
+		return 0, err
+	}
+	return len(data), nil // file size tracked against uncompressed content for rotation purposes
+}
+
+// rotate closes the current file (if any) and opens
+// "<prefix>-YYYYMMDDHHMMSS-NNNNN.warc.gz", writing a fresh warcinfo record
+// at its head. The timestamp lets operators sort/locate a crawl's output by
+// when it was written; the sequence number still disambiguates multiple
+// rotations within the same second.
+func (w *Writer) rotate() error {
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			return fmt.Errorf("closing WARC file: %w", err)
+		}
+	}
+
+	name := fmt.Sprintf("%s-%s-%05d.warc.gz", w.cfg.WARCFilePrefix, time.Now().UTC().Format("20060102150405"), w.seq)
+	w.seq++
+
+	f, err := os.Create(filepath.Join(w.cfg.WARCOutputDir, name))
+	if err != nil {
+		return fmt.Errorf("creating WARC file %q: %w", name, err)
+	}
+	w.file = f
+	w.written = 0
+
+	info := newWARCInfoRecord(w.software, "WARC File Format 1.1")
+	if _, err := w.writeGzipMember(info.bytes()); err != nil {
+		return fmt.Errorf("writing warcinfo record: %w", err)
+	}
+	w.written += int64(len(info.content))
+	w.stats.IncrementWARCRecords("warcinfo")
+
+	w.log.WithField("file", name).Info("Opened new WARC file")
+	return nil
+}
+
+// Close flushes and releases the current WARC file. Safe to call on a nil
+// *Writer (i.e. when WARC archival is disabled).
+func (w *Writer) Close() error {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	return w.file.Close()
+}